@@ -0,0 +1,104 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Account is GET /api/v1/account, Balances only holds assets with a non
+// zero free or locked amount. Commissions are in basis points.
+type Account struct {
+	MakerCommission  int            `json:"makerCommission"`
+	TakerCommission  int            `json:"takerCommission"`
+	BuyerCommission  int            `json:"buyerCommission"`
+	SellerCommission int            `json:"sellerCommission"`
+	CanTrade         bool           `json:"canTrade"`
+	CanWithdraw      bool           `json:"canWithdraw"`
+	CanDeposit       bool           `json:"canDeposit"`
+	AccountType      string         `json:"accountType"`
+	Permissions      []string       `json:"permissions"`
+	UpdateTime       int64          `json:"updateTime"`
+	Balances         []*BalanceData `json:"balances"`
+
+	balanceOnce sync.Once
+	balanceMap  map[string]*BalanceData
+}
+
+func (account *Account) UpdatedAt() time.Time {
+	return msToTime(account.UpdateTime)
+}
+
+// Balance looks up an asset case insensitive, nil if the account has none.
+// It is safe for concurrent use, the lookup map is built on the first call.
+func (account *Account) Balance(asset string) *BalanceData {
+	account.balanceOnce.Do(func() {
+		account.balanceMap = make(map[string]*BalanceData, len(account.Balances))
+		for _, balanceData := range account.Balances {
+			account.balanceMap[strings.ToUpper(balanceData.Currency)] = balanceData
+		}
+	})
+	return account.balanceMap[strings.ToUpper(asset)]
+}
+
+func (account *Account) Free(asset string) float64 {
+	if balanceData := account.Balance(asset); balanceData != nil {
+		return balanceData.GetFree()
+	}
+	return 0
+}
+
+func (account *Account) Locked(asset string) float64 {
+	if balanceData := account.Balance(asset); balanceData != nil {
+		return balanceData.GetLock()
+	}
+	return 0
+}
+
+// HasPermission reports whether permissions contains p, e.g. SPOT.
+func (account *Account) HasPermission(p string) bool {
+	for _, permission := range account.Permissions {
+		if strings.EqualFold(permission, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// 获取账户信息, one request for all balances
+func (ex *Exchange) GetAccount() (*Account, error) {
+	return ex.GetAccountCtx(context.Background())
+}
+
+func (ex *Exchange) GetAccountCtx(ctx context.Context) (*Account, error) {
+	account, err := ex.getAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]*BalanceData, 0, len(account.Balances))
+	for _, balanceData := range account.Balances {
+		if balanceData.Free.Sign() != 0 || balanceData.Locked.Sign() != 0 {
+			balances = append(balances, balanceData)
+		}
+	}
+	account.Balances = balances
+	return account, nil
+}
+
+// getAccount keeps the zero balances.
+func (ex *Exchange) getAccount(ctx context.Context) (*Account, error) {
+	params := make(map[string]string)
+	body, err := ex.client.Signed(ctx, GET, "/api/v1/account", params)
+	if err != nil {
+		return nil, err
+	}
+	account := &Account{}
+	err = json.Unmarshal([]byte(body), account)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
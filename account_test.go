@@ -0,0 +1,57 @@
+package bitrue
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestGetAccount(t *testing.T) {
+	requests := 0
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"makerCommission":10,"takerCommission":20,"canTrade":true,"permissions":["SPOT"],"updateTime":1570000000000,
+			"balances":[{"asset":"btr","free":"100.5","locked":"1"},{"asset":"USDT","free":"0","locked":"0"},{"asset":"XRP","free":"0","locked":"2"}]}`))
+	})
+	defer server.Close()
+
+	account, err := ex.GetAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(account.Balances) != 2 || account.Balance("USDT") != nil {
+		t.Fatalf("zero balances should be dropped: %v", account.Balances)
+	}
+	if account.Free("BTR") != 100.5 || account.Locked("xrp") != 2 {
+		t.Fatalf("unexpected balances %v", account.Balances)
+	}
+	if account.TakerCommission != 20 || !account.CanTrade || !account.HasPermission("spot") || account.UpdatedAt().Unix() != 1570000000 {
+		t.Fatalf("unexpected account %+v", account)
+	}
+
+	usdt, err := ex.GetBalance("usdt")
+	if err != nil || usdt.GetFree() != 0 {
+		t.Fatalf("GetBalance should find zero balances case insensitive: %v %v", usdt, err)
+	}
+	if _, err := ex.GetBalance("ETH"); err != ErrUnknownAsset {
+		t.Fatalf("expected ErrUnknownAsset, got %v", err)
+	}
+	if requests != 3 {
+		t.Fatalf("unexpected request count %d", requests)
+	}
+}
+
+func TestAccountBalanceConcurrent(t *testing.T) {
+	account := &Account{Balances: []*BalanceData{{Currency: "BTR"}, {Currency: "USDT"}}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if account.Balance("btr") == nil || account.Balance("usdt") == nil {
+				t.Error("missing balance")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,48 +1,83 @@
 package bitrue
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/ericlagergren/decimal"
-	"github.com/kr/pretty"
 	"github.com/spf13/cast"
 	"github.com/tidwall/gjson"
 	"log"
 	"math"
 	"strings"
+	"time"
 )
 
 type Exchange struct {
 	symbols           map[string]string
 	SymbolInfos       []*SymbolData
+	RateLimits        []RateLimit
 	MinQuoteAmountMap map[string]float64
+	// SkipValidation disables the ValidateOrder check before submitting.
+	SkipValidation bool
+	// ClientOrderIDGenerator sets newClientOrderId of orders without one.
+	ClientOrderIDGenerator ClientOrderIDGenerator
+
+	client *Client
 }
 
 var accessKey string
 var secretKey string
 
-func NewExchange(ak string, sk string) *Exchange {
+// NewExchange panics when exchangeInfo can not be loaded, use
+// NewExchangeWithClient to handle the error.
+func NewExchange(ak string, sk string, opts ...Option) *Exchange {
 	accessKey = ak
 	secretKey = sk
+	opts = append([]Option{WithBaseURL(https)}, opts...)
+	ex, err := NewExchangeWithClient(NewClient(ak, sk, opts...))
+	if err != nil {
+		log.Panicln(err)
+	}
+	return ex
+}
+
+// NewExchangeWithClient builds an Exchange on top of an existing Client,
+// so several accounts can live side by side. It loads exchangeInfo.
+func NewExchangeWithClient(client *Client) (*Exchange, error) {
+	return NewExchangeWithClientCtx(context.Background(), client)
+}
+
+func NewExchangeWithClientCtx(ctx context.Context, client *Client) (*Exchange, error) {
 	ex := &Exchange{
 		MinQuoteAmountMap: make(map[string]float64),
+		client:            client,
+	}
+	if err := ex.getSymbols(ctx); err != nil {
+		return nil, err
 	}
-	ex.getSymbols()
 	ex.initMinQuoteAmount()
-	return ex
+	return ex, nil
 }
 
 func SetHost(host string) {
 	if host != "" {
 		https = host
+		defaultClient.BaseURL = strings.TrimRight(host, "/")
 	}
 }
 
-//need set yourself
+// Client returns the rest client used by the exchange.
+func (ex *Exchange) Client() *Client {
+	return ex.client
+}
+
+// min quote amount of every symbol, from its MIN_NOTIONAL filter
 func (ex *Exchange) initMinQuoteAmount() {
-	ex.MinQuoteAmountMap["BTRUSDT"] = 10
-	ex.MinQuoteAmountMap["BTRXRP"] = 10
-	ex.MinQuoteAmountMap["BTRBTC"] = 0.0001
-	ex.MinQuoteAmountMap["BTRETH"] = 0.01
+	for _, symbolInfo := range ex.SymbolInfos {
+		if minNotional := symbolInfo.MinNotional(); minNotional != nil {
+			ex.MinQuoteAmountMap[symbolInfo.Symbol], _ = minNotional.Float64()
+		}
+	}
 }
 
 func (ex *Exchange) GetQuoteAmount(symbol string) (float64, bool) {
@@ -53,16 +88,30 @@ func (ex *Exchange) GetQuoteAmount(symbol string) (float64, bool) {
 	return 0, false
 }
 
-func (ex *Exchange) getSymbols() {
-	body := HttpGetRequest(https+"/api/v1/exchangeInfo", nil)
-	//log.Println(body)
+// Current exchange trading rules and symbol information
+func (ex *Exchange) getSymbols(ctx context.Context) error {
+	body, err := ex.client.Get(ctx, "/api/v1/exchangeInfo", nil)
+	if err != nil {
+		return err
+	}
 	data := gjson.Get(body, "symbols")
 	symbolInfos := make([]*SymbolData, 0)
-	err := json.Unmarshal([]byte(data.String()), &symbolInfos)
+	err = json.Unmarshal([]byte(data.String()), &symbolInfos)
 	if err != nil {
-		log.Panicln(err, body)
+		return err
 	}
 	ex.SymbolInfos = symbolInfos
+
+	rateLimits := make([]RateLimit, 0)
+	if data := gjson.Get(body, "rateLimits"); data.Exists() {
+		err = json.Unmarshal([]byte(data.Raw), &rateLimits)
+		if err != nil {
+			return err
+		}
+	}
+	ex.RateLimits = rateLimits
+	ex.client.SetRateLimits(rateLimits)
+	return nil
 }
 
 func (ex *Exchange) GetSymbolInfo(symbol string) *SymbolData {
@@ -75,172 +124,276 @@ func (ex *Exchange) GetSymbolInfo(symbol string) *SymbolData {
 	return nil
 }
 
-func (ex *Exchange) GetDepth(symbol string) *Depth {
+// 获取Bitrue服务器时间
+func (ex *Exchange) GetServerTime() (time.Time, error) {
+	return ex.GetServerTimeCtx(context.Background())
+}
+
+func (ex *Exchange) GetServerTimeCtx(ctx context.Context) (time.Time, error) {
+	return ex.client.ServerTime(ctx)
+}
+
+// 获取深度数据
+func (ex *Exchange) GetDepth(symbol string) (*Depth, error) {
+	return ex.GetDepthCtx(context.Background(), symbol)
+}
+
+func (ex *Exchange) GetDepthCtx(ctx context.Context, symbol string) (*Depth, error) {
+	return ex.GetDepthLimitCtx(ctx, symbol, 0)
+}
+
+// GetDepthLimit returns at most limit levels per side, limit <= 0 uses the
+// server default.
+func (ex *Exchange) GetDepthLimit(symbol string, limit int) (*Depth, error) {
+	return ex.GetDepthLimitCtx(context.Background(), symbol, limit)
+}
+
+func (ex *Exchange) GetDepthLimitCtx(ctx context.Context, symbol string, limit int) (*Depth, error) {
 	params := make(map[string]string)
 	params["symbol"] = symbol
-	body := HttpGetRequest(https+"/api/v1/depth", params)
+	if limit > 0 {
+		params["limit"] = cast.ToString(limit)
+	}
+	body, err := ex.client.Get(ctx, "/api/v1/depth", params)
+	if err != nil {
+		return nil, err
+	}
 	depth := &Depth{}
-	err := json.Unmarshal([]byte(body), depth)
+	err = json.Unmarshal([]byte(body), depth)
 	if err != nil {
-		log.Println(err, body)
-		return nil
+		return nil, err
 	}
-	return depth
+	return depth, nil
+}
+
+// 获取交易对最新价
+func (ex *Exchange) GetTickerPrice(symbol string) (*decimal.Big, error) {
+	return ex.GetTickerPriceCtx(context.Background(), symbol)
 }
 
-func (ex *Exchange) GetTickerPrice(symbol string) *decimal.Big {
+func (ex *Exchange) GetTickerPriceCtx(ctx context.Context, symbol string) (*decimal.Big, error) {
 	params := make(map[string]string)
 	params["symbol"] = symbol
-	body := HttpGetRequest(https+"/api/v1/ticker/price", params)
+	body, err := ex.client.Get(ctx, "/api/v1/ticker/price", params)
+	if err != nil {
+		return nil, err
+	}
 	priceTicker := &PriceTicker{}
-	err := json.Unmarshal([]byte(body), priceTicker)
+	err = json.Unmarshal([]byte(body), priceTicker)
 	if err != nil {
-		log.Println(err, body)
+		return nil, err
 	}
-	return priceTicker.Price
+	return priceTicker.Price, nil
 }
 
-func (ex *Exchange) GetBookTicker(symbol string) *BookTicker {
-	return GetTicker(symbol)
+// Best price/qty on the order book for a symbol.
+func (ex *Exchange) GetBookTicker(symbol string) (*BookTicker, error) {
+	return ex.GetBookTickerCtx(context.Background(), symbol)
 }
 
-func (ex *Exchange) GetBuyPrice(symbol string) float64 {
-	bookTicker := ex.GetBookTicker(symbol)
-	buyPrice, _ := bookTicker.BidPrice.Float64()
-	return buyPrice
+func (ex *Exchange) GetBookTickerCtx(ctx context.Context, symbol string) (*BookTicker, error) {
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	body, err := ex.client.Get(ctx, "/api/v1/ticker/bookTicker", params)
+	if err != nil {
+		return nil, err
+	}
+
+	bookTicker := &BookTicker{}
+	err = json.Unmarshal([]byte(body), bookTicker)
+	if err != nil {
+		return nil, err
+	}
+	return bookTicker, nil
 }
 
-func (ex *Exchange) GetSellPrice(symbol string) float64 {
-	bookTicker := ex.GetBookTicker(symbol)
-	sellPrice, _ := bookTicker.AskPrice.Float64()
-	return sellPrice
+func (ex *Exchange) GetBuyPrice(symbol string) (float64, error) {
+	return ex.GetBuyPriceCtx(context.Background(), symbol)
 }
 
-func (ex *Exchange) get24hr(symbol string) {
-	params := make(map[string]string)
-	params["symbol"] = symbol
+func (ex *Exchange) GetBuyPriceCtx(ctx context.Context, symbol string) (float64, error) {
+	bookTicker, err := ex.GetBookTickerCtx(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return bookTicker.GetBuyPrice(), nil
+}
 
-	body := HttpGetRequest(https+"/api/v1/ticker/24hr", params)
-	println(body)
+func (ex *Exchange) GetSellPrice(symbol string) (float64, error) {
+	return ex.GetSellPriceCtx(context.Background(), symbol)
 }
 
-func println(str string) {
-	m := make([]interface{}, 0)
-	err := json.Unmarshal([]byte(str), &m)
+func (ex *Exchange) GetSellPriceCtx(ctx context.Context, symbol string) (float64, error) {
+	bookTicker, err := ex.GetBookTickerCtx(ctx, symbol)
 	if err != nil {
-		log.Println(str)
+		return 0, err
 	}
-	log.Println(pretty.Formatter(m))
+	return bookTicker.GetSellPrice(), nil
 }
 
 //return orderId
-func (ex *Exchange) BuyLimit(symbol string, price float64, amount float64) int64 {
-	params := make(map[string]string)
-	params["type"] = "LIMIT"
-	params["symbol"] = symbol
-	params["side"] = "BUY"
-	params["price"] = cast.ToString(price)
-	params["quantity"] = cast.ToString(amount)
-	data := SignedRequest(POST, https+"/api/v1/order", params)
+func (ex *Exchange) BuyLimit(symbol string, price float64, amount float64) (int64, error) {
+	return ex.BuyLimitCtx(context.Background(), symbol, price, amount)
+}
 
-	orderId := gjson.Get(data, "orderId").Int()
-	if orderId == 0 {
-		log.Println(data, symbol, price, amount)
-	}
-	return orderId
+func (ex *Exchange) BuyLimitCtx(ctx context.Context, symbol string, price float64, amount float64) (int64, error) {
+	return ex.BuyLimitDecimalCtx(ctx, symbol, DecimalFromFloat(price), DecimalFromFloat(amount))
 }
 
-func (ex *Exchange) SellLimit(symbol string, price float64, amount float64) int64 {
-	params := make(map[string]string)
-	params["type"] = "LIMIT"
-	params["symbol"] = symbol
-	params["side"] = "SELL"
-	params["price"] = cast.ToString(price)
-	params["quantity"] = cast.ToString(amount)
-	data := SignedRequest(POST, https+"/api/v1/order", params)
-	orderId := gjson.Get(data, "orderId").Int()
-	if orderId == 0 {
-		log.Println(data, symbol, price, amount)
+func (ex *Exchange) SellLimit(symbol string, price float64, amount float64) (int64, error) {
+	return ex.SellLimitCtx(context.Background(), symbol, price, amount)
+}
+
+func (ex *Exchange) SellLimitCtx(ctx context.Context, symbol string, price float64, amount float64) (int64, error) {
+	return ex.SellLimitDecimalCtx(ctx, symbol, DecimalFromFloat(price), DecimalFromFloat(amount))
+}
+
+// BuyLimitDecimal sends price and amount exactly as given, round them with
+// RoundPrice and RoundQuantity first.
+func (ex *Exchange) BuyLimitDecimal(symbol string, price, amount *decimal.Big) (int64, error) {
+	return ex.BuyLimitDecimalCtx(context.Background(), symbol, price, amount)
+}
+
+func (ex *Exchange) BuyLimitDecimalCtx(ctx context.Context, symbol string, price, amount *decimal.Big) (int64, error) {
+	return ex.limitOrder(ctx, symbol, SideBuy, price, amount)
+}
+
+func (ex *Exchange) SellLimitDecimal(symbol string, price, amount *decimal.Big) (int64, error) {
+	return ex.SellLimitDecimalCtx(context.Background(), symbol, price, amount)
+}
+
+func (ex *Exchange) SellLimitDecimalCtx(ctx context.Context, symbol string, price, amount *decimal.Big) (int64, error) {
+	return ex.limitOrder(ctx, symbol, SideSell, price, amount)
+}
+
+// limitOrder returns an *OrderError carrying the newClientOrderId when
+// sending fails, see PlaceOrder.
+func (ex *Exchange) limitOrder(ctx context.Context, symbol, side string, price, amount *decimal.Big) (int64, error) {
+	ack, err := ex.PlaceOrder(ctx, NewLimitOrder(symbol, side, price, amount))
+	if err != nil {
+		return 0, err
 	}
-	return orderId
+	return ack.OrderId, nil
 }
 
-func (ex *Exchange) QueryOrder(symbol string, orderId int64) *OrderData {
+func (ex *Exchange) QueryOrder(symbol string, orderId int64) (*OrderData, error) {
+	return ex.QueryOrderCtx(context.Background(), symbol, orderId)
+}
+
+func (ex *Exchange) QueryOrderCtx(ctx context.Context, symbol string, orderId int64) (*OrderData, error) {
 	params := make(map[string]string)
 	params["symbol"] = symbol
 	params["orderId"] = cast.ToString(orderId)
 
-	body := SignedRequest(GET, https+"/api/v1/order", params)
+	body, err := ex.client.Signed(ctx, GET, "/api/v1/order", params)
+	if err != nil {
+		return nil, err
+	}
 	order := &OrderData{}
-	err := json.Unmarshal([]byte(body), order)
+	err = json.Unmarshal([]byte(body), order)
 	if err != nil {
-		log.Println(err, body, params)
-		return nil
+		return nil, err
 	}
-	return order
+	return order, nil
 }
 
-func (ex *Exchange) QueryOpenOrders(symbol string) []*OrderData {
+func (ex *Exchange) QueryOpenOrders(symbol string) ([]*OrderData, error) {
+	return ex.QueryOpenOrdersCtx(context.Background(), symbol)
+}
+
+func (ex *Exchange) QueryOpenOrdersCtx(ctx context.Context, symbol string) ([]*OrderData, error) {
 	params := make(map[string]string)
 	params["symbol"] = symbol
-	body := SignedRequest(GET, https+"/api/v1/openOrders", params)
+	body, err := ex.client.Signed(ctx, GET, "/api/v1/openOrders", params)
+	if err != nil {
+		return nil, err
+	}
 	var orders []*OrderData
-	err := json.Unmarshal([]byte(body), &orders)
+	err = json.Unmarshal([]byte(body), &orders)
 	if err != nil {
-		log.Println(err, body)
+		return nil, err
 	}
-	return orders
+	return orders, nil
 }
 
-func (ex *Exchange) GetBalance(currency string) *BalanceData {
-	params := make(map[string]string)
-	body := SignedRequest(GET, https+"/api/v1/account", params)
-	balance := &Balance{}
-	err := json.Unmarshal([]byte(body), balance)
+func (ex *Exchange) GetBalance(currency string) (*BalanceData, error) {
+	return ex.GetBalanceCtx(context.Background(), currency)
+}
+
+// GetBalanceCtx looks up currency case insensitive, use GetAccount to read
+// several assets with one request.
+func (ex *Exchange) GetBalanceCtx(ctx context.Context, currency string) (*BalanceData, error) {
+	account, err := ex.getAccount(ctx)
 	if err != nil {
-		log.Println(err, body)
-		return nil
+		return nil, err
 	}
-
-	for _, balanceData := range balance.Balances {
-		if balanceData.Currency == currency {
-			return balanceData
-		}
+	if balanceData := account.Balance(currency); balanceData != nil {
+		return balanceData, nil
 	}
-	return nil
+	return nil, ErrUnknownAsset
+}
+
+func (ex *Exchange) Cancel(symbol string, orderId int64) (*DeleteReturn, error) {
+	return ex.CancelCtx(context.Background(), symbol, orderId)
 }
 
-func (ex *Exchange) Cancel(symbol string, orderId int64) bool {
+func (ex *Exchange) CancelCtx(ctx context.Context, symbol string, orderId int64) (*DeleteReturn, error) {
 	params := make(map[string]string)
 	params["symbol"] = symbol
 	params["orderId"] = cast.ToString(orderId)
-	body := SignedRequest(DELETE, https+"/api/v1/order", params)
-	log.Println(body)
-	if gjson.Get(body, "orderId").Exists() {
-		return true
+	body, err := ex.client.Signed(ctx, DELETE, "/api/v1/order", params)
+	if err != nil {
+		return nil, err
 	}
-	log.Println(body)
-	return false
+	return parseDeleteReturn(body), nil
 }
 
+// TruncPrice truncates price to the tick size of the symbol.
 func (ex *Exchange) TruncPrice(symbol string, price float64) (float64, bool) {
-	symbolInfo := ex.GetSymbolInfo(symbol)
-	if symbolInfo != nil {
-		pre := math.Pow10(symbolInfo.QuotePrecision)
-		tPrice := math.Round(price*pre) / pre
-		return tPrice, true
+	tPrice, err := ex.RoundPrice(symbol, DecimalFromFloat(price), RoundDown)
+	if err != nil {
+		return 0, false
 	}
-	return 0, false
+	f, _ := tPrice.Float64()
+	return f, true
 }
 
+// TruncAmount truncates amount to the step size of the symbol.
 func (ex *Exchange) TruncAmount(symbol string, amount float64) (float64, bool) {
+	tAmount, err := ex.RoundQuantity(symbol, DecimalFromFloat(amount), RoundDown)
+	if err != nil {
+		return 0, false
+	}
+	f, _ := tAmount.Float64()
+	return f, true
+}
+
+// RoundPrice rounds price to the PRICE_FILTER tick size, or to the quote
+// precision if the symbol has no tick size.
+func (ex *Exchange) RoundPrice(symbol string, price *decimal.Big, mode RoundingMode) (*decimal.Big, error) {
 	symbolInfo := ex.GetSymbolInfo(symbol)
-	if symbolInfo != nil {
-		pre := math.Pow10(symbolInfo.BasePrecision)
-		tAmount := math.Round(amount*pre) / pre
-		return tAmount, true
+	if symbolInfo == nil {
+		return nil, ErrUnknownSymbol
 	}
-	return 0, false
+	step := scaleStep(symbolInfo.QuotePrecision)
+	if priceFilter := symbolInfo.PriceFilter(); priceFilter != nil && !isZero(priceFilter.TickSize) {
+		step = priceFilter.TickSize
+	}
+	return RoundToStep(price, step, mode), nil
+}
+
+// RoundQuantity rounds amount to the LOT_SIZE step size, or to the base
+// precision if the symbol has no step size.
+func (ex *Exchange) RoundQuantity(symbol string, amount *decimal.Big, mode RoundingMode) (*decimal.Big, error) {
+	symbolInfo := ex.GetSymbolInfo(symbol)
+	if symbolInfo == nil {
+		return nil, ErrUnknownSymbol
+	}
+	step := scaleStep(symbolInfo.BasePrecision)
+	if lotSize := symbolInfo.LotSizeFilter(); lotSize != nil && !isZero(lotSize.StepSize) {
+		step = lotSize.StepSize
+	}
+	return RoundToStep(amount, step, mode), nil
 }
 
 func (ex *Exchange) GetTiny(symbol string) float64 {
@@ -251,49 +404,68 @@ func (ex *Exchange) GetTiny(symbol string) float64 {
 	return 0
 }
 
-func (ex *Exchange) GetOrderMap(symbol string) map[int64]*OrderData {
-	orders := ex.QueryOpenOrders(symbol)
-	if orders == nil {
-		return nil
+func (ex *Exchange) GetOrderMap(symbol string) (map[int64]*OrderData, error) {
+	return ex.GetOrderMapCtx(context.Background(), symbol)
+}
+
+func (ex *Exchange) GetOrderMapCtx(ctx context.Context, symbol string) (map[int64]*OrderData, error) {
+	orders, err := ex.QueryOpenOrdersCtx(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
 	orderMap := make(map[int64]*OrderData)
 	for _, order := range orders {
 		orderMap[order.OrderId] = order
 	}
-	return orderMap
+	return orderMap, nil
 }
 
-func GetTrades(symbol string, limit int64) []Trade {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-	params["limit"] = cast.ToString(limit)
-	body := HttpGetRequest(https+"/api/v1/trades", params)
-	trades := make([]Trade, 0)
-	err := json.Unmarshal([]byte(body), &trades)
-	if err != nil {
-		log.Println(err)
-	}
-	return trades
+// GetTrades uses the host set by SetHost, see Exchange.GetTrades.
+func GetTrades(symbol string, limit int64) ([]Trade, error) {
+	return GetTradesCtx(context.Background(), symbol, limit)
+}
+
+func GetTradesCtx(ctx context.Context, symbol string, limit int64) ([]Trade, error) {
+	return getTrades(ctx, defaultClient, symbol, limit)
 }
 
-func GetTicker(symbol string) *BookTicker {
+func GetTicker(symbol string) (*BookTicker, error) {
+	return GetTickerCtx(context.Background(), symbol)
+}
+
+func GetTickerCtx(ctx context.Context, symbol string) (*BookTicker, error) {
 	params := make(map[string]string)
 	params["symbol"] = symbol
-	body := HttpGetRequest(https+"/api/v1/ticker/bookTicker", params)
+	body, err := HttpGetRequestCtx(ctx, https+"/api/v1/ticker/bookTicker", params)
+	if err != nil {
+		return nil, err
+	}
 
 	bookTicker := &BookTicker{}
-	err := json.Unmarshal([]byte(body), bookTicker)
+	err = json.Unmarshal([]byte(body), bookTicker)
 	if err != nil {
-		log.Println(err, body)
-		return nil
+		return nil, err
 	}
-	return bookTicker
+	return bookTicker, nil
 }
 
-func GetMidPrice(symbol string) float64 {
-	ticker := GetTicker(symbol)
-	if ticker == nil {
-		return 0
+// GetServerTime returns the server time of the host set by SetHost.
+func GetServerTime() (time.Time, error) {
+	return GetServerTimeCtx(context.Background())
+}
+
+func GetServerTimeCtx(ctx context.Context) (time.Time, error) {
+	return defaultClient.ServerTime(ctx)
+}
+
+func GetMidPrice(symbol string) (float64, error) {
+	return GetMidPriceCtx(context.Background(), symbol)
+}
+
+func GetMidPriceCtx(ctx context.Context, symbol string) (float64, error) {
+	ticker, err := GetTickerCtx(ctx, symbol)
+	if err != nil {
+		return 0, err
 	}
-	return (ticker.GetBuyPrice() + ticker.GetSellPrice()) / 2
+	return (ticker.GetBuyPrice() + ticker.GetSellPrice()) / 2, nil
 }
@@ -0,0 +1,110 @@
+package bitrue
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testExchangeInfo = `{
+	"timezone": "UTC",
+	"rateLimits": [
+		{"rateLimitType": "REQUEST_WEIGHT", "interval": "MINUTE", "limit": 1200},
+		{"rateLimitType": "ORDERS", "interval": "SECOND", "limit": 10}
+	],
+	"symbols": [{
+		"symbol": "BTRUSDT",
+		"status": "TRADING",
+		"baseAsset": "BTR",
+		"baseAssetPrecision": 1,
+		"quoteAsset": "USDT",
+		"quotePrecision": 4,
+		"orderTypes": ["LIMIT", "MARKET"],
+		"icebergAllowed": false,
+		"filters": [
+			{"filterType": "PRICE_FILTER", "minPrice": "0.0001", "maxPrice": "100", "priceScale": 4},
+			{"filterType": "LOT_SIZE", "minQty": "1", "minVal": "10", "maxQty": "1000000", "volumeScale": 1}
+		]
+	}, {
+		"symbol": "ETHBTC",
+		"status": "BREAK",
+		"baseAsset": "ETH",
+		"baseAssetPrecision": 3,
+		"quoteAsset": "BTC",
+		"quotePrecision": 6,
+		"orderTypes": ["LIMIT", "LIMIT_MAKER", "MARKET"],
+		"filters": [
+			{"filterType": "PRICE_FILTER", "minPrice": "0.000001", "maxPrice": "100", "tickSize": "0.000001"},
+			{"filterType": "LOT_SIZE", "minQty": "0.001", "maxQty": "10000", "stepSize": "0.001"},
+			{"filterType": "MIN_NOTIONAL", "minNotional": "0.0001"}
+		]
+	}]
+}`
+
+// newTestExchange serves exchangeInfo and passes every other request to handler.
+func newTestExchange(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Exchange, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/exchangeInfo" {
+			w.Write([]byte(testExchangeInfo))
+			return
+		}
+		if handler == nil {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			return
+		}
+		handler(w, r)
+	}))
+	opts = append([]Option{WithBaseURL(server.URL)}, opts...)
+	ex, err := NewExchangeWithClient(NewClient("ak", "sk", opts...))
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return ex, server
+}
+
+func TestSymbolFilters(t *testing.T) {
+	ex, server := newTestExchange(t, nil)
+	defer server.Close()
+
+	if len(ex.RateLimits) != 2 {
+		t.Fatalf("unexpected rate limits %+v", ex.RateLimits)
+	}
+
+	btr := ex.GetSymbolInfo("btrusdt")
+	if got := btr.PriceFilter().TickSize.String(); got != "0.0001" {
+		t.Fatalf("tick size from priceScale: %s", got)
+	}
+	if got := btr.LotSizeFilter().StepSize.String(); got != "0.1" {
+		t.Fatalf("step size from volumeScale: %s", got)
+	}
+	if !btr.SupportsOrderType("MARKET") || btr.SupportsOrderType("LIMIT_MAKER") {
+		t.Fatalf("unexpected order types %v", btr.OrderTypes)
+	}
+
+	eth := ex.GetSymbolInfo("ETHBTC")
+	if got := eth.LotSizeFilter().StepSize.String(); got != "0.001" {
+		t.Fatalf("unexpected step size %s", got)
+	}
+
+	if a, ok := ex.GetQuoteAmount("BTRUSDT"); !ok || a != 10 {
+		t.Fatalf("min quote amount from minVal: %v %v", a, ok)
+	}
+	if a, ok := ex.GetQuoteAmount("ethbtc"); !ok || a != 0.0001 {
+		t.Fatalf("min quote amount from MIN_NOTIONAL: %v %v", a, ok)
+	}
+}
+
+func TestNewExchangeWithClientError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ex, err := NewExchangeWithClient(NewClient("ak", "sk", WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{})))
+	var apiErr *APIError
+	if ex != nil || !errors.As(err, &apiErr) || apiErr.HTTPStatus != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 error, got %v %v", ex, err)
+	}
+}
@@ -1,244 +1,77 @@
 package bitrue
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/ericlagergren/decimal"
-	"github.com/kr/pretty"
 	"github.com/monkeybang/bitrue"
 	"github.com/spf13/cast"
-	"github.com/tidwall/gjson"
 	"log"
-	"math"
 	"strconv"
-	"strings"
 	"time"
 )
 
+// Exchange is a per account client, the common rest methods come from the
+// embedded bitrue.Exchange which is bound to AppKey, SecretKey and Host.
 type Exchange struct {
-	AppKey            string `json:"app_key"`
-	SecretKey         string `json:"secret_key"`
-	Host              string `json:"host"`
-	symbols           map[string]string
-	SymbolInfos       []*bitrue.SymbolData
-	MinQuoteAmountMap map[string]float64
+	AppKey    string `json:"app_key"`
+	SecretKey string `json:"secret_key"`
+	Host      string `json:"host"`
+	*bitrue.Exchange
 }
 
-func NewExchange(ak, sk, host string) *Exchange {
-	ex := &Exchange{
-		AppKey:            ak,
-		SecretKey:         sk,
-		Host:              host,
-		MinQuoteAmountMap: make(map[string]float64),
-	}
-	ex.getSymbols()
-	ex.initMinQuoteAmount()
-	return ex
-}
-
-//need set yourself
-func (ex *Exchange) initMinQuoteAmount() {
-	ex.MinQuoteAmountMap["BTRUSDT"] = 10
-	ex.MinQuoteAmountMap["BTRXRP"] = 10
-	ex.MinQuoteAmountMap["BTRBTC"] = 0.0001
-	ex.MinQuoteAmountMap["BTRETH"] = 0.01
-}
-
-// Current exchange trading rules and symbol information
-func (ex *Exchange) getSymbols() {
-	body := bitrue.HttpGetRequest(ex.Host+"/api/v1/exchangeInfo", nil)
-	//log.Println(body)
-	data := gjson.Get(body, "symbols")
-	symbolInfos := make([]*bitrue.SymbolData, 0)
-	err := json.Unmarshal([]byte(data.String()), &symbolInfos)
-	if err != nil {
-		log.Panicln("getSymbols error", err)
-	}
-	ex.SymbolInfos = symbolInfos
-}
-
-func (ex *Exchange) GetQuoteAmount(symbol string) (float64, bool) {
-	symbol = strings.ToUpper(symbol)
-	if a, ok := ex.MinQuoteAmountMap[symbol]; ok {
-		return a, true
-	}
-	return 0, false
-}
-
-func (ex *Exchange) GetSymbolInfo(symbol string) *bitrue.SymbolData {
-	symbol = strings.ToUpper(symbol)
-	for _, symbolInfo := range ex.SymbolInfos {
-		if symbolInfo.Symbol == symbol {
-			return symbolInfo
-		}
-	}
-	return nil
-}
-
-// 获取深度数据
-func (ex *Exchange) GetDepth(symbol string) *bitrue.Depth {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-	body := bitrue.HttpGetRequest(ex.Host+"/api/v1/depth", params)
-	depth := &bitrue.Depth{}
-	err := json.Unmarshal([]byte(body), depth)
-	if err != nil {
-		log.Println(err, body)
-		return nil
-	}
-	return depth
-}
-
-// 获取交易对最新价
-func (ex *Exchange) GetTickerPrice(symbol string) *decimal.Big {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-	body := bitrue.HttpGetRequest(ex.Host+"/api/v1/ticker/price", params)
-	priceTicker := &bitrue.PriceTicker{}
-	err := json.Unmarshal([]byte(body), priceTicker)
+// NewExchange panics when exchangeInfo can not be loaded, use
+// NewExchangeCtx to handle the error.
+func NewExchange(ak, sk, host string, opts ...bitrue.Option) *Exchange {
+	ex, err := NewExchangeCtx(context.Background(), ak, sk, host, opts...)
 	if err != nil {
-		log.Println(err, body)
+		log.Panicln(err)
 	}
-	return priceTicker.Price
-}
-
-// Best price/qty on the order book for a symbol or symbols.
-func (ex *Exchange) GetBookTicker(symbol string) *bitrue.BookTicker {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-	body := bitrue.HttpGetRequest(ex.Host+"/api/v1/ticker/bookTicker", params)
-
-	bookTicker := &bitrue.BookTicker{}
-	err := json.Unmarshal([]byte(body), bookTicker)
-	if err != nil {
-		log.Println(err, body)
-	}
-	return bookTicker
-}
-
-func (ex *Exchange) GetBuyPrice(symbol string) float64 {
-	bookTicker := ex.GetBookTicker(symbol)
-	buyPrice, _ := bookTicker.BidPrice.Float64()
-	return buyPrice
-}
-
-func (ex *Exchange) GetSellPrice(symbol string) float64 {
-	bookTicker := ex.GetBookTicker(symbol)
-	sellPrice, _ := bookTicker.AskPrice.Float64()
-	return sellPrice
-}
-
-// 24小时内的价格变化
-func (ex *Exchange) get24hr(symbol string) {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-
-	body := bitrue.HttpGetRequest(ex.Host+"/api/v1/ticker/24hr", params)
-	println(body)
+	return ex
 }
 
-func println(str string) {
-	m := make([]interface{}, 0)
-	err := json.Unmarshal([]byte(str), &m)
+func NewExchangeCtx(ctx context.Context, ak, sk, host string, opts ...bitrue.Option) (*Exchange, error) {
+	opts = append([]bitrue.Option{bitrue.WithBaseURL(host)}, opts...)
+	client := bitrue.NewClient(ak, sk, opts...)
+	exchange, err := bitrue.NewExchangeWithClientCtx(ctx, client)
 	if err != nil {
-		log.Println(str)
-	}
-	log.Println(pretty.Formatter(m))
-}
-
-// return orderId
-func (ex *Exchange) BuyLimit(symbol string, price float64, amount float64) int64 {
-	params := make(map[string]string)
-	params["type"] = "LIMIT"
-	params["symbol"] = symbol
-	params["side"] = "BUY"
-	params["price"] = cast.ToString(price)
-	params["quantity"] = cast.ToString(amount)
-
-	data := bitrue.SignedRequestWithKey(bitrue.POST, ex.Host+"/api/v1/order", params, ex.AppKey, ex.SecretKey)
-
-	orderId := gjson.Get(data, "orderId").Int()
-	if orderId == 0 {
-		log.Println(data, symbol, price, amount)
+		return nil, err
 	}
-	return orderId
+	return &Exchange{
+		AppKey:    ak,
+		SecretKey: sk,
+		Host:      client.BaseURL,
+		Exchange:  exchange,
+	}, nil
 }
 
-func (ex *Exchange) BuyMarket(symbol string, price float64, amount float64) int64 {
-	params := make(map[string]string)
-	params["type"] = "MARKET"
-	params["symbol"] = symbol
-	params["side"] = "BUY"
-	params["price"] = cast.ToString(price)
-	params["quantity"] = cast.ToString(amount)
-
-	data := bitrue.SignedRequestWithKey(bitrue.POST, ex.Host+"/api/v1/order", params, ex.AppKey, ex.SecretKey)
-
-	orderId := gjson.Get(data, "orderId").Int()
-	if orderId == 0 {
-		log.Println(data, symbol, price, amount)
-	}
-	return orderId
+// BuyMarket spends quoteAmount of the quote asset, e.g. 100 USDT of BTRUSDT.
+// The returned ack carries the fills, see OrderAck.AvgPrice.
+func (ex *Exchange) BuyMarket(symbol string, quoteAmount float64) (*bitrue.OrderAck, error) {
+	return ex.BuyMarketCtx(context.Background(), symbol, quoteAmount)
 }
 
-//
-func (ex *Exchange) SellLimit(symbol string, price float64, amount float64) int64 {
-	params := make(map[string]string)
-	params["type"] = "LIMIT"
-	params["symbol"] = symbol
-	params["side"] = "SELL"
-	params["price"] = cast.ToString(price)
-	params["quantity"] = cast.ToString(amount)
-	data := bitrue.SignedRequestWithKey(bitrue.POST, ex.Host+"/api/v1/order", params, ex.AppKey, ex.SecretKey)
-	orderId := gjson.Get(data, "orderId").Int()
-	if orderId == 0 {
-		log.Println(data, symbol, price, amount)
-	}
-	return orderId
+func (ex *Exchange) BuyMarketCtx(ctx context.Context, symbol string, quoteAmount float64) (*bitrue.OrderAck, error) {
+	req := bitrue.NewMarketQuoteOrder(symbol, bitrue.SideBuy, bitrue.DecimalFromFloat(quoteAmount))
+	req.NewOrderRespType = bitrue.OrderRespTypeFull
+	return ex.PlaceOrder(ctx, req)
 }
 
-func (ex *Exchange) SellMarket(symbol string, price float64, amount float64) int64 {
-	params := make(map[string]string)
-	params["type"] = "MARKET"
-	params["symbol"] = symbol
-	params["side"] = "SELL"
-	params["price"] = cast.ToString(price)
-	params["quantity"] = cast.ToString(amount)
-	data := bitrue.SignedRequestWithKey(bitrue.POST, ex.Host+"/api/v1/order", params, ex.AppKey, ex.SecretKey)
-	orderId := gjson.Get(data, "orderId").Int()
-	if orderId == 0 {
-		log.Println(data, symbol, price, amount)
-	}
-	return orderId
+// SellMarket sells amount of the base asset.
+func (ex *Exchange) SellMarket(symbol string, amount float64) (*bitrue.OrderAck, error) {
+	return ex.SellMarketCtx(context.Background(), symbol, amount)
 }
 
-func (ex *Exchange) QueryOrder(symbol string, orderId int64) *bitrue.OrderData {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-	params["orderId"] = cast.ToString(orderId)
-
-	body := bitrue.SignedRequestWithKey(bitrue.GET, ex.Host+"/api/v1/order", params, ex.AppKey, ex.SecretKey)
-	order := &bitrue.OrderData{}
-	err := json.Unmarshal([]byte(body), order)
-	if err != nil {
-		log.Println(err, body, params)
-		return nil
-	}
-	return order
+func (ex *Exchange) SellMarketCtx(ctx context.Context, symbol string, amount float64) (*bitrue.OrderAck, error) {
+	req := bitrue.NewMarketOrder(symbol, bitrue.SideSell, bitrue.DecimalFromFloat(amount))
+	req.NewOrderRespType = bitrue.OrderRespTypeFull
+	return ex.PlaceOrder(ctx, req)
 }
 
-func (ex *Exchange) QueryOpenOrders(symbol string) []*bitrue.OrderData {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-	body := bitrue.SignedRequestWithKey(bitrue.GET, ex.Host+"/api/v1/openOrders", params, ex.AppKey, ex.SecretKey)
-	var orders []*bitrue.OrderData
-	err := json.Unmarshal([]byte(body), &orders)
-	if err != nil {
-		log.Println(err, body)
-	}
-	return orders
+func (ex *Exchange) QueryAllOrders(symbol string, orderId int64, limit int) ([]*bitrue.OrderData, error) {
+	return ex.QueryAllOrdersCtx(context.Background(), symbol, orderId, limit)
 }
 
-func (ex *Exchange) QueryAllOrders(symbol string, orderId int64, limit int) []*bitrue.OrderData {
+func (ex *Exchange) QueryAllOrdersCtx(ctx context.Context, symbol string, orderId int64, limit int) ([]*bitrue.OrderData, error) {
 	params := make(map[string]string)
 	params["symbol"] = symbol
 	if orderId > 0 {
@@ -250,83 +83,16 @@ func (ex *Exchange) QueryAllOrders(symbol string, orderId int64, limit int) []*b
 	}
 	params["limit"] = strconv.Itoa(limit)
 
-	body := bitrue.SignedRequestWithKey(bitrue.GET, ex.Host+"/api/v1/allOrders", params, ex.AppKey, ex.SecretKey)
-	var orders []*bitrue.OrderData
-	err := json.Unmarshal([]byte(body), &orders)
+	body, err := ex.Client().Signed(ctx, bitrue.GET, "/api/v1/allOrders", params)
 	if err != nil {
-		log.Println(err, body)
+		return nil, err
 	}
-	return orders
-}
-
-func (ex *Exchange) GetBalance(currency string) *bitrue.BalanceData {
-	params := make(map[string]string)
-	body := bitrue.SignedRequestWithKey(bitrue.GET, ex.Host+"/api/v1/account", params, ex.AppKey, ex.SecretKey)
-	balance := &bitrue.Balance{}
-	err := json.Unmarshal([]byte(body), balance)
+	var orders []*bitrue.OrderData
+	err = json.Unmarshal([]byte(body), &orders)
 	if err != nil {
-		log.Println(err, body)
-		return nil
-	}
-
-	for _, balanceData := range balance.Balances {
-		if balanceData.Currency == currency {
-			return balanceData
-		}
-	}
-	return nil
-}
-
-func (ex *Exchange) Cancel(symbol string, orderId int64) bool {
-	params := make(map[string]string)
-	params["symbol"] = symbol
-	params["orderId"] = cast.ToString(orderId)
-	body := bitrue.SignedRequestWithKey(bitrue.DELETE, ex.Host+"/api/v1/order", params, ex.AppKey, ex.SecretKey)
-	if body[:7] == `{"code"` {
-		log.Println(body)
-		return false
-	}
-	return true
-}
-
-func (ex *Exchange) TruncPrice(symbol string, price float64) (float64, bool) {
-	symbolInfo := ex.GetSymbolInfo(symbol)
-	if symbolInfo != nil {
-		pre := math.Pow10(symbolInfo.QuotePrecision)
-		tPrice := math.Round(price*pre) / pre
-		return tPrice, true
+		return nil, err
 	}
-	return 0, false
-}
-
-func (ex *Exchange) TruncAmount(symbol string, amount float64) (float64, bool) {
-	symbolInfo := ex.GetSymbolInfo(symbol)
-	if symbolInfo != nil {
-		pre := math.Pow10(symbolInfo.BasePrecision)
-		tAmount := math.Round(amount*pre) / pre
-		return tAmount, true
-	}
-	return 0, false
-}
-
-func (ex *Exchange) GetTiny(symbol string) float64 {
-	symbolInfo := ex.GetSymbolInfo(symbol)
-	if symbolInfo != nil {
-		return 1 / math.Pow10(symbolInfo.QuotePrecision)
-	}
-	return 0
-}
-
-func (ex *Exchange) GetOrderMap(symbol string) map[int64]*bitrue.OrderData {
-	orders := ex.QueryOpenOrders(symbol)
-	if orders == nil {
-		return nil
-	}
-	orderMap := make(map[int64]*bitrue.OrderData)
-	for _, order := range orders {
-		orderMap[order.OrderId] = order
-	}
-	return orderMap
+	return orders, nil
 }
 
 // 获取本地当前时间
@@ -334,7 +100,11 @@ func GetCurrentLocalTime() string {
 	return cast.ToString(time.Now().UnixNano() / 1000000)
 }
 
-// TODO 获取Bitrue服务器时间
-func GetCurrentServerTime() string {
-	return ""
+// 获取Bitrue服务器时间
+func GetCurrentServerTime() (string, error) {
+	serverTime, err := bitrue.GetServerTime()
+	if err != nil {
+		return "", err
+	}
+	return cast.ToString(serverTime.UnixNano() / 1000000), nil
 }
@@ -0,0 +1,94 @@
+package bitrue
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/monkeybang/bitrue"
+)
+
+const testExchangeInfo = `{
+	"symbols": [{
+		"symbol": "BTRUSDT",
+		"status": "TRADING",
+		"baseAsset": "BTR",
+		"baseAssetPrecision": 1,
+		"quoteAsset": "USDT",
+		"quotePrecision": 4,
+		"orderTypes": ["LIMIT", "MARKET"],
+		"filters": [
+			{"filterType": "PRICE_FILTER", "minPrice": "0.0001", "maxPrice": "100", "priceScale": 4},
+			{"filterType": "LOT_SIZE", "minQty": "1", "minVal": "10", "maxQty": "1000000", "volumeScale": 1}
+		]
+	}]
+}`
+
+func TestMarketOrders(t *testing.T) {
+	var orders []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/exchangeInfo":
+			w.Write([]byte(testExchangeInfo))
+		case "/api/v1/order":
+			body, _ := ioutil.ReadAll(r.Body)
+			values, _ := url.ParseQuery(string(body))
+			orders = append(orders, values)
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"7","status":"FILLED","executedQty":"100","cummulativeQuoteQty":"12",
+				"fills":[{"price":"0.12","qty":"100","commission":"0.1","commissionAsset":"BTR"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	ex := NewExchange("ak", "sk", server.URL)
+	ack, err := ex.BuyMarket("BTRUSDT", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.OrderId != 7 || len(ack.Fills) != 1 || ack.AvgPrice().Cmp(ack.Fills[0].Price) != 0 {
+		t.Fatalf("unexpected ack %+v", ack)
+	}
+	if _, err := ex.SellMarket("BTRUSDT", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("expected 2 orders, got %d", len(orders))
+	}
+	buy, sell := orders[0], orders[1]
+	if buy.Get("side") != "BUY" || buy.Get("type") != "MARKET" || buy.Get("quoteOrderQty") != "12" || buy.Get("newOrderRespType") != "FULL" {
+		t.Fatalf("unexpected buy %v", buy)
+	}
+	if _, ok := buy["quantity"]; ok {
+		t.Fatalf("market buy must not send quantity: %v", buy)
+	}
+	if _, ok := buy["price"]; ok {
+		t.Fatalf("market buy must not send price: %v", buy)
+	}
+	if sell.Get("side") != "SELL" || sell.Get("type") != "MARKET" || sell.Get("quantity") != "100" {
+		t.Fatalf("unexpected sell %v", sell)
+	}
+	if _, ok := sell["quoteOrderQty"]; ok {
+		t.Fatalf("market sell must not send quoteOrderQty: %v", sell)
+	}
+	if _, ok := sell["price"]; ok {
+		t.Fatalf("market sell must not send price: %v", sell)
+	}
+}
+
+func TestNewExchangeCtxError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ex, err := NewExchangeCtx(context.Background(), "ak", "sk", server.URL, bitrue.WithRetryPolicy(bitrue.RetryPolicy{}))
+	if ex != nil || err == nil {
+		t.Fatalf("expected error, got %v %v", ex, err)
+	}
+}
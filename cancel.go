@@ -0,0 +1,111 @@
+package bitrue
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// cancelConcurrency bounds the parallel cancel requests, the rate limiter
+// of the client still applies to each of them.
+const cancelConcurrency = 5
+
+type CancelStatus string
+
+const (
+	CancelStatusCancelled     CancelStatus = "CANCELLED"
+	CancelStatusAlreadyFilled CancelStatus = "ALREADY_FILLED"
+	CancelStatusUnknown       CancelStatus = "UNKNOWN"
+	CancelStatusError         CancelStatus = "ERROR"
+)
+
+// CancelResult is the outcome of one order of CancelOrders, Order is the
+// final order state when the cancel was rejected, Err is set for
+// CancelStatusError.
+type CancelResult struct {
+	OrderId int64
+	Status  CancelStatus
+	Order   *OrderData
+	Err     error
+}
+
+// CancelAllOpenOrders cancels every open order of symbol.
+func (ex *Exchange) CancelAllOpenOrders(symbol string) (map[int64]*CancelResult, error) {
+	return ex.CancelAllOpenOrdersCtx(context.Background(), symbol)
+}
+
+func (ex *Exchange) CancelAllOpenOrdersCtx(ctx context.Context, symbol string) (map[int64]*CancelResult, error) {
+	orderMap, err := ex.GetOrderMapCtx(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(orderMap))
+	for orderId := range orderMap {
+		ids = append(ids, orderId)
+	}
+	return ex.CancelOrdersCtx(ctx, symbol, ids)
+}
+
+// CancelOrders cancels ids concurrently. A rejected cancel is looked up to
+// tell an order which filled meanwhile from an unknown one. The error is
+// only set when ctx is done, per order errors are in the results.
+func (ex *Exchange) CancelOrders(symbol string, ids []int64) (map[int64]*CancelResult, error) {
+	return ex.CancelOrdersCtx(context.Background(), symbol, ids)
+}
+
+func (ex *Exchange) CancelOrdersCtx(ctx context.Context, symbol string, ids []int64) (map[int64]*CancelResult, error) {
+	results := make(map[int64]*CancelResult, len(ids))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cancelConcurrency)
+	for _, orderId := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(orderId int64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result := ex.cancelOrder(ctx, symbol, orderId)
+			mu.Lock()
+			results[orderId] = result
+			mu.Unlock()
+		}(orderId)
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+func (ex *Exchange) cancelOrder(ctx context.Context, symbol string, orderId int64) *CancelResult {
+	result := &CancelResult{OrderId: orderId}
+	_, err := ex.CancelCtx(ctx, symbol, orderId)
+	if err == nil {
+		result.Status = CancelStatusCancelled
+		return result
+	}
+	if !errors.Is(err, ErrUnknownOrder) {
+		result.Status = CancelStatusError
+		result.Err = err
+		return result
+	}
+
+	order, queryErr := ex.QueryOrderCtx(ctx, symbol, orderId)
+	switch {
+	case errors.Is(queryErr, ErrUnknownOrder):
+		result.Status = CancelStatusUnknown
+	case queryErr != nil:
+		result.Status = CancelStatusError
+		result.Err = queryErr
+	case order.IsFilled():
+		result.Status = CancelStatusAlreadyFilled
+		result.Order = order
+	case order.Status == "CANCELED":
+		result.Status = CancelStatusCancelled
+		result.Order = order
+	default:
+		result.Status = CancelStatusError
+		result.Order = order
+		result.Err = err
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package bitrue
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestCancelAllOpenOrders(t *testing.T) {
+	var mu sync.Mutex
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		params, _ := url.ParseQuery(string(body))
+		switch {
+		case r.URL.Path == "/api/v1/openOrders":
+			w.Write([]byte(`[{"symbol":"BTRUSDT","orderId":"1"},{"symbol":"BTRUSDT","orderId":"2"},{"symbol":"BTRUSDT","orderId":"3"},{"symbol":"BTRUSDT","orderId":"4"}]`))
+		case r.Method == DELETE && params.Get("orderId") == "1":
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"1"}`))
+		case r.Method == DELETE && params.Get("orderId") == "4":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1100,"msg":"Illegal characters found in parameter"}`))
+		case r.Method == DELETE:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-2011,"msg":"Unknown order sent."}`))
+		case r.Method == GET && params.Get("orderId") == "2":
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"2","status":"FILLED","executedQty":"10"}`))
+		case r.Method == GET:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+		}
+	})
+	defer server.Close()
+
+	results, err := ex.CancelAllOpenOrders("BTRUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64]CancelStatus{
+		1: CancelStatusCancelled,
+		2: CancelStatusAlreadyFilled,
+		3: CancelStatusUnknown,
+		4: CancelStatusError,
+	}
+	for orderId, status := range want {
+		if results[orderId] == nil || results[orderId].Status != status {
+			t.Errorf("order %d: expected %s, got %+v", orderId, status, results[orderId])
+		}
+	}
+	if results[2].Order.Filled() != 10 || results[4].Err == nil {
+		t.Fatalf("unexpected results %+v %+v", results[2], results[4])
+	}
+}
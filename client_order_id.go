@@ -0,0 +1,101 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+)
+
+// ClientOrderIDGenerator makes the newClientOrderId of orders which do not
+// set one. Ids must be unique per account, at most 36 chars of
+// [.A-Za-z0-9:/_-].
+type ClientOrderIDGenerator interface {
+	NextClientOrderID() string
+}
+
+// TagSequenceGenerator generates ids like "grid1-42" from a strategy tag and
+// a sequence number. Persist Last() and pass it back as start after a restart
+// to keep the ids deterministic.
+type TagSequenceGenerator struct {
+	// first field for 64 bit atomic alignment
+	seq uint64
+	Tag string
+}
+
+func NewTagSequenceGenerator(tag string, start uint64) *TagSequenceGenerator {
+	return &TagSequenceGenerator{seq: start, Tag: tag}
+}
+
+func (g *TagSequenceGenerator) NextClientOrderID() string {
+	return g.Tag + "-" + strconv.FormatUint(atomic.AddUint64(&g.seq, 1), 10)
+}
+
+// Last returns the last sequence number handed out.
+func (g *TagSequenceGenerator) Last() uint64 {
+	return atomic.LoadUint64(&g.seq)
+}
+
+// ParseClientOrderID splits an id made by TagSequenceGenerator.
+func ParseClientOrderID(id string) (tag string, seq uint64, ok bool) {
+	i := strings.LastIndex(id, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return id[:i], seq, true
+}
+
+func (ex *Exchange) QueryOrderByClientID(symbol string, clientOrderId string) (*OrderData, error) {
+	return ex.QueryOrderByClientIDCtx(context.Background(), symbol, clientOrderId)
+}
+
+func (ex *Exchange) QueryOrderByClientIDCtx(ctx context.Context, symbol string, clientOrderId string) (*OrderData, error) {
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	params["origClientOrderId"] = clientOrderId
+
+	body, err := ex.client.Signed(ctx, GET, "/api/v1/order", params)
+	if err != nil {
+		return nil, err
+	}
+	order := &OrderData{}
+	err = json.Unmarshal([]byte(body), order)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (ex *Exchange) CancelByClientID(symbol string, clientOrderId string) (*DeleteReturn, error) {
+	return ex.CancelByClientIDCtx(context.Background(), symbol, clientOrderId)
+}
+
+func (ex *Exchange) CancelByClientIDCtx(ctx context.Context, symbol string, clientOrderId string) (*DeleteReturn, error) {
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	params["origClientOrderId"] = clientOrderId
+	body, err := ex.client.Signed(ctx, DELETE, "/api/v1/order", params)
+	if err != nil {
+		return nil, err
+	}
+	return parseDeleteReturn(body), nil
+}
+
+func parseDeleteReturn(body string) *DeleteReturn {
+	clientOrderId := gjson.Get(body, "origClientOrderId").String()
+	if clientOrderId == "" {
+		clientOrderId = gjson.Get(body, "clientOrderId").String()
+	}
+	return &DeleteReturn{
+		Symbol:        gjson.Get(body, "symbol").String(),
+		OrderId:       gjson.Get(body, "orderId").Int(),
+		ClientOrderId: clientOrderId,
+	}
+}
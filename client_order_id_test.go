@@ -0,0 +1,107 @@
+package bitrue
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTagSequenceGenerator(t *testing.T) {
+	g := NewTagSequenceGenerator("grid1", 41)
+	id := g.NextClientOrderID()
+	if id != "grid1-42" || g.Last() != 42 {
+		t.Fatalf("unexpected id %s", id)
+	}
+	tag, seq, ok := ParseClientOrderID(id)
+	if !ok || tag != "grid1" || seq != 42 {
+		t.Fatalf("unexpected parse %s %d %v", tag, seq, ok)
+	}
+	if _, _, ok := ParseClientOrderID("nodash"); ok {
+		t.Fatal("expected parse failure")
+	}
+}
+
+func TestClientOrderIDs(t *testing.T) {
+	var sent url.Values
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent, _ = url.ParseQuery(string(body))
+		switch r.Method {
+		case POST:
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"5","clientOrderId":"` + sent.Get("newClientOrderId") + `"}`))
+		case GET:
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"5","clientOrderId":"` + sent.Get("origClientOrderId") + `","status":"NEW"}`))
+		case DELETE:
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"5","origClientOrderId":"` + sent.Get("origClientOrderId") + `"}`))
+		}
+	})
+	defer server.Close()
+	ex.ClientOrderIDGenerator = NewTagSequenceGenerator("mm", 0)
+
+	if _, err := ex.BuyLimit("BTRUSDT", 0.1, 100); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Get("newClientOrderId") != "mm-1" {
+		t.Fatalf("generator was not used: %v", sent)
+	}
+
+	order, err := ex.QueryOrderByClientID("BTRUSDT", "mm-1")
+	if err != nil || order.ClientOrderId != "mm-1" || order.OrderId != 5 {
+		t.Fatalf("unexpected order %v %v", order, err)
+	}
+	cancelled, err := ex.CancelByClientID("BTRUSDT", "mm-1")
+	if err != nil || cancelled.ClientOrderId != "mm-1" || cancelled.OrderId != 5 {
+		t.Fatalf("unexpected cancel %+v %v", cancelled, err)
+	}
+}
+
+func TestPlaceOrderTimeoutKeepsClientID(t *testing.T) {
+	var mu sync.Mutex
+	var landed string
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		values, _ := url.ParseQuery(string(body))
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case POST:
+			// the order lands, but the answer comes too late
+			landed = values.Get("newClientOrderId")
+			mu.Unlock()
+			time.Sleep(200 * time.Millisecond)
+			mu.Lock()
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"9"}`))
+		case GET:
+			if values.Get("origClientOrderId") != landed {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+				return
+			}
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"9","clientOrderId":"` + landed + `","status":"NEW"}`))
+		}
+	}, WithTimeout(50*time.Millisecond), WithRetryPolicy(RetryPolicy{}))
+	defer server.Close()
+	ex.ClientOrderIDGenerator = NewTagSequenceGenerator("bot", 0)
+
+	_, err := ex.BuyLimit("BTRUSDT", 0.2, 100)
+	var orderErr *OrderError
+	if !errors.As(err, &orderErr) || orderErr.ClientOrderID != "bot-1" {
+		t.Fatalf("expected an OrderError with the client id, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the timeout to be wrapped, got %v", err)
+	}
+	order, err := ex.QueryOrderByClientIDCtx(context.Background(), "BTRUSDT", orderErr.ClientOrderID)
+	if err != nil || order.OrderId != 9 || order.ClientOrderId != "bot-1" {
+		t.Fatalf("unexpected order %+v %v", order, err)
+	}
+
+	if id := ex.NextClientOrderID(); id != "bot-2" {
+		t.Fatalf("unexpected next id %s", id)
+	}
+}
@@ -0,0 +1,67 @@
+package bitrue
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// DecimalFromFloat uses the shortest representation of f, so 0.3 stays 0.3
+// instead of 0.299999999999999988897769753748...
+func DecimalFromFloat(f float64) *decimal.Big {
+	d, _ := new(decimal.Big).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+// isMultiple reports whether x is an integer multiple of step.
+func isMultiple(x, step *decimal.Big) bool {
+	if isZero(step) {
+		return true
+	}
+	rem := decimal.Context128.Rem(new(decimal.Big), x, step)
+	return rem.IsFinite() && rem.Sign() == 0
+}
+
+type RoundingMode int
+
+const (
+	// RoundDown rounds toward zero.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundNearest rounds half away from zero.
+	RoundNearest
+)
+
+// RoundToStep rounds x to an integer multiple of step, e.g. a tick size or
+// a lot step size. x is returned unchanged when step is zero.
+func RoundToStep(x, step *decimal.Big, mode RoundingMode) *decimal.Big {
+	if isZero(step) {
+		return new(decimal.Big).Copy(x)
+	}
+	ctx := decimal.Context128
+	step = new(decimal.Big).Abs(step)
+	steps := ctx.QuoInt(new(decimal.Big), x, step)
+	rem := ctx.Sub(new(decimal.Big), x, ctx.Mul(new(decimal.Big), steps, step))
+	if rem.Sign() != 0 {
+		away := false
+		switch mode {
+		case RoundUp:
+			away = true
+		case RoundNearest:
+			twice := ctx.Mul(new(decimal.Big), rem, decimal.New(2, 0))
+			away = twice.CmpAbs(step) >= 0
+		}
+		if away {
+			ctx.Add(steps, steps, decimal.New(int64(x.Sign()), 0))
+		}
+	}
+	return ctx.Mul(new(decimal.Big), steps, step)
+}
+
+// FormatDecimal formats d in plain notation as bitrue expects it, never
+// with an exponent.
+func FormatDecimal(d *decimal.Big) string {
+	return fmt.Sprintf("%f", d)
+}
@@ -0,0 +1,67 @@
+package bitrue
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRoundToStep(t *testing.T) {
+	tests := []struct {
+		x, step string
+		mode    RoundingMode
+		want    string
+	}{
+		{"0.12345", "0.0001", RoundDown, "0.1234"},
+		{"0.12345", "0.0001", RoundUp, "0.1235"},
+		{"0.12345", "0.0001", RoundNearest, "0.1235"},
+		{"0.12344", "0.0001", RoundNearest, "0.1234"},
+		{"0.1234", "0.0001", RoundUp, "0.1234"},
+		{"17", "5", RoundDown, "15"},
+		{"17", "5", RoundNearest, "15"},
+		{"-0.125", "0.01", RoundDown, "-0.12"},
+		{"1.5", "0", RoundDown, "1.5"},
+	}
+	for _, tt := range tests {
+		got := FormatDecimal(RoundToStep(dec(tt.x), dec(tt.step), tt.mode))
+		if got != tt.want {
+			t.Errorf("RoundToStep(%s, %s, %d) = %s, want %s", tt.x, tt.step, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	for in, want := range map[string]string{"1e-8": "0.00000001", "1E+3": "1000", "0.3": "0.3"} {
+		if got := FormatDecimal(dec(in)); got != want {
+			t.Errorf("FormatDecimal(%s) = %s, want %s", in, got, want)
+		}
+	}
+	if got := FormatDecimal(DecimalFromFloat(0.00000123)); got != "0.00000123" {
+		t.Errorf("DecimalFromFloat lost precision: %s", got)
+	}
+}
+
+func TestBuyLimitDecimal(t *testing.T) {
+	var sent url.Values
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent, _ = url.ParseQuery(string(body))
+		w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"42"}`))
+	})
+	defer server.Close()
+
+	price, _ := ex.RoundPrice("BTRUSDT", dec("0.123456"), RoundDown)
+	amount, _ := ex.RoundQuantity("BTRUSDT", dec("1e2"), RoundDown)
+	orderId, err := ex.BuyLimitDecimal("BTRUSDT", price, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orderId != 42 || sent.Get("price") != "0.1234" || sent.Get("quantity") != "100.0" {
+		t.Fatalf("unexpected order %d %v", orderId, sent)
+	}
+
+	if p, ok := ex.TruncPrice("BTRUSDT", 0.12349); !ok || p != 0.1234 {
+		t.Fatalf("TruncPrice should truncate, got %v", p)
+	}
+}
@@ -0,0 +1,162 @@
+package bitrue
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ericlagergren/decimal"
+)
+
+var (
+	ErrEmptyBook = errors.New("bitrue: order book side is empty")
+	ErrDepthRow  = errors.New("bitrue: depth row out of range")
+	// ErrInsufficientDepth is returned when the book can not fill the
+	// requested size.
+	ErrInsufficientDepth = errors.New("bitrue: not enough depth to fill size")
+)
+
+var bpsFactor = decimal.New(10000, 0)
+
+func depthLevel(levels [][2]*decimal.Big, row int) ([2]*decimal.Big, error) {
+	if len(levels) == 0 {
+		return [2]*decimal.Big{}, ErrEmptyBook
+	}
+	if row < 0 || row >= len(levels) {
+		return [2]*decimal.Big{}, fmt.Errorf("%w: row %d of %d", ErrDepthRow, row, len(levels))
+	}
+	return levels[row], nil
+}
+
+// MidPrice is the average of the best bid and the best ask.
+func (depth *Depth) MidPrice() (*decimal.Big, error) {
+	bid, err := depthLevel(depth.Bids, 0)
+	if err != nil {
+		return nil, err
+	}
+	ask, err := depthLevel(depth.Asks, 0)
+	if err != nil {
+		return nil, err
+	}
+	ctx := decimal.Context128
+	mid := ctx.Add(new(decimal.Big), bid[0], ask[0])
+	return ctx.Quo(mid, mid, decimal.New(2, 0)), nil
+}
+
+// SpreadBps is the best ask minus the best bid in basis points of the mid
+// price.
+func (depth *Depth) SpreadBps() (*decimal.Big, error) {
+	mid, err := depth.MidPrice()
+	if err != nil {
+		return nil, err
+	}
+	ctx := decimal.Context128
+	spread := ctx.Sub(new(decimal.Big), depth.Asks[0][0], depth.Bids[0][0])
+	ctx.Mul(spread, spread, bpsFactor)
+	return ctx.Quo(spread, spread, mid), nil
+}
+
+// CumulativeBids sums the quantity of all bids priced at or above price.
+func (depth *Depth) CumulativeBids(price *decimal.Big) *decimal.Big {
+	total := new(decimal.Big)
+	for _, level := range depth.Bids {
+		if level[0].Cmp(price) < 0 {
+			break
+		}
+		decimal.Context128.Add(total, total, level[1])
+	}
+	return total
+}
+
+// CumulativeAsks sums the quantity of all asks priced at or below price.
+func (depth *Depth) CumulativeAsks(price *decimal.Big) *decimal.Big {
+	total := new(decimal.Big)
+	for _, level := range depth.Asks {
+		if level[0].Cmp(price) > 0 {
+			break
+		}
+		decimal.Context128.Add(total, total, level[1])
+	}
+	return total
+}
+
+// VWAPBuy is the average price paid for buying qty base currency against
+// the asks.
+func (depth *Depth) VWAPBuy(qty *decimal.Big) (*decimal.Big, error) {
+	return vwap(depth.Asks, qty)
+}
+
+// VWAPSell is the average price received for selling qty base currency
+// against the bids.
+func (depth *Depth) VWAPSell(qty *decimal.Big) (*decimal.Big, error) {
+	return vwap(depth.Bids, qty)
+}
+
+func vwap(levels [][2]*decimal.Big, qty *decimal.Big) (*decimal.Big, error) {
+	if err := checkSize(qty); err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		return nil, ErrEmptyBook
+	}
+	f := walkBook(levels, qty, false)
+	if !f.complete {
+		return nil, fmt.Errorf("%w: %s available, %s wanted", ErrInsufficientDepth, FormatDecimal(f.base), FormatDecimal(qty))
+	}
+	return decimal.Context128.Quo(new(decimal.Big), f.quote, f.base), nil
+}
+
+func checkSize(size *decimal.Big) error {
+	if size == nil {
+		return errors.New("bitrue: size is required")
+	}
+	if size.Sign() <= 0 {
+		return fmt.Errorf("bitrue: size must be positive, got %s", FormatDecimal(size))
+	}
+	return nil
+}
+
+// bookFill is the result of taking liquidity from one side of the book.
+type bookFill struct {
+	base     *decimal.Big // base quantity filled
+	quote    *decimal.Big // quote amount filled
+	worst    *decimal.Big // price of the last level touched
+	levels   int
+	complete bool
+}
+
+// walkBook takes size from levels, best price first. size is a base
+// quantity, or a quote amount when quote is set.
+func walkBook(levels [][2]*decimal.Big, size *decimal.Big, quote bool) bookFill {
+	ctx := decimal.Context128
+	f := bookFill{base: new(decimal.Big), quote: new(decimal.Big)}
+	remaining := new(decimal.Big).Copy(size)
+	for _, level := range levels {
+		if remaining.Sign() <= 0 {
+			break
+		}
+		price, qty := level[0], level[1]
+		if price.Sign() <= 0 || qty.Sign() <= 0 {
+			continue
+		}
+		cost := ctx.Mul(new(decimal.Big), qty, price)
+		if quote {
+			if cost.Cmp(remaining) > 0 {
+				cost = new(decimal.Big).Copy(remaining)
+				qty = ctx.Quo(new(decimal.Big), remaining, price)
+			}
+			ctx.Sub(remaining, remaining, cost)
+		} else {
+			if qty.Cmp(remaining) > 0 {
+				qty = new(decimal.Big).Copy(remaining)
+				cost = ctx.Mul(new(decimal.Big), qty, price)
+			}
+			ctx.Sub(remaining, remaining, qty)
+		}
+		ctx.Add(f.base, f.base, qty)
+		ctx.Add(f.quote, f.quote, cost)
+		f.worst = price
+		f.levels++
+	}
+	f.complete = remaining.Sign() <= 0
+	return f
+}
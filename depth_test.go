@@ -0,0 +1,136 @@
+package bitrue
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ericlagergren/decimal"
+)
+
+func testDepth() *Depth {
+	return &Depth{
+		Bids: [][2]*decimal.Big{{dec("0.99"), dec("10")}, {dec("0.98"), dec("20")}},
+		Asks: [][2]*decimal.Big{{dec("1.01"), dec("10")}, {dec("1.02"), dec("20")}, {dec("1.05"), dec("5")}},
+	}
+}
+
+func TestDepthAccessors(t *testing.T) {
+	depth := testDepth()
+	if price, err := depth.AsksPrice(2); err != nil || price != 1.05 {
+		t.Fatalf("unexpected ask price %v %v", price, err)
+	}
+	if _, err := depth.BidsPrice(2); !errors.Is(err, ErrDepthRow) {
+		t.Fatalf("expected ErrDepthRow, got %v", err)
+	}
+	if amount, err := depth.DepthBidsAmountAll(1); err != nil || amount != 30 {
+		t.Fatalf("unexpected bids amount %v %v", amount, err)
+	}
+	if _, err := depth.DepthAsksAmountAll(-1); !errors.Is(err, ErrDepthRow) {
+		t.Fatalf("expected ErrDepthRow, got %v", err)
+	}
+	empty := &Depth{}
+	if _, err := empty.Section(); !errors.Is(err, ErrEmptyBook) {
+		t.Fatalf("expected ErrEmptyBook, got %v", err)
+	}
+	if _, err := empty.MidPrice(); !errors.Is(err, ErrEmptyBook) {
+		t.Fatalf("expected ErrEmptyBook, got %v", err)
+	}
+}
+
+func TestDepthAnalytics(t *testing.T) {
+	depth := testDepth()
+	mid, err := depth.MidPrice()
+	if err != nil || mid.Cmp(dec("1")) != 0 {
+		t.Fatalf("unexpected mid %v %v", mid, err)
+	}
+	bps, err := depth.SpreadBps()
+	if err != nil || bps.Cmp(dec("200")) != 0 {
+		t.Fatalf("unexpected spread %v %v", bps, err)
+	}
+	if got := depth.CumulativeAsks(dec("1.02")); got.Cmp(dec("30")) != 0 {
+		t.Fatalf("unexpected cumulative asks %v", got)
+	}
+	if got := depth.CumulativeBids(dec("1")); got.Sign() != 0 {
+		t.Fatalf("unexpected cumulative bids %v", got)
+	}
+	// 10 @ 1.01 + 10 @ 1.02 = 20.3
+	price, err := depth.VWAPBuy(dec("20"))
+	if err != nil || price.Cmp(dec("1.015")) != 0 {
+		t.Fatalf("unexpected buy vwap %v %v", price, err)
+	}
+	price, err = depth.VWAPSell(dec("5"))
+	if err != nil || price.Cmp(dec("0.99")) != 0 {
+		t.Fatalf("unexpected sell vwap %v %v", price, err)
+	}
+	if _, err := depth.VWAPSell(dec("31")); !errors.Is(err, ErrInsufficientDepth) {
+		t.Fatalf("expected ErrInsufficientDepth, got %v", err)
+	}
+}
+
+func TestGetDepthLimit(t *testing.T) {
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/depth" || r.URL.Query().Get("limit") != "5" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"lastUpdateId":1,"bids":[["0.99","10"]],"asks":[["1.01","3"]]}`))
+	})
+	defer server.Close()
+
+	depth, err := ex.GetDepthLimit("BTRUSDT", 5)
+	if err != nil || len(depth.Bids) != 1 || depth.Asks[0][1].Cmp(dec("3")) != 0 {
+		t.Fatalf("unexpected depth %+v %v", depth, err)
+	}
+}
+
+func TestSimulateMarketOrders(t *testing.T) {
+	depth := testDepth()
+	buy, err := depth.SimulateMarketBuy(dec("20.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buy.TooThin || buy.Levels != 2 || buy.Filled.Cmp(dec("20")) != 0 || buy.WorstPrice.Cmp(dec("1.02")) != 0 {
+		t.Fatalf("unexpected buy impact %+v", buy)
+	}
+	if buy.AvgPrice.Cmp(dec("1.015")) != 0 || buy.SlippageBps.Cmp(dec("150")) != 0 {
+		t.Fatalf("unexpected buy price %v slippage %v", buy.AvgPrice, buy.SlippageBps)
+	}
+
+	sell, err := depth.SimulateMarketSell(dec("35"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sell.TooThin || sell.Levels != 2 || sell.Filled.Cmp(dec("30")) != 0 || sell.Amount.Cmp(dec("29.5")) != 0 {
+		t.Fatalf("unexpected sell impact %+v", sell)
+	}
+
+	if _, err := depth.SimulateMarketBuy(dec("0")); err == nil {
+		t.Fatal("expected error for zero size")
+	}
+	if _, err := (&Depth{Asks: depth.Asks}).SimulateMarketBuy(dec("1")); !errors.Is(err, ErrEmptyBook) {
+		t.Fatalf("expected ErrEmptyBook, got %v", err)
+	}
+}
+
+func TestDepthInvalidSizes(t *testing.T) {
+	depth := testDepth()
+	if _, err := depth.SimulateMarketBuy(nil); err == nil {
+		t.Fatal("expected error for nil size")
+	}
+	if _, err := depth.VWAPBuy(nil); err == nil {
+		t.Fatal("expected error for nil size")
+	}
+	empty := &Depth{
+		Bids: [][2]*decimal.Big{{dec("0.99"), dec("0")}},
+		Asks: [][2]*decimal.Big{{dec("1.01"), dec("0")}, {dec("1.02"), dec("0")}},
+	}
+	if _, err := empty.SimulateMarketBuy(dec("10")); !errors.Is(err, ErrInsufficientDepth) {
+		t.Fatalf("expected ErrInsufficientDepth, got %v", err)
+	}
+	if _, err := empty.SimulateMarketSell(dec("10")); !errors.Is(err, ErrInsufficientDepth) {
+		t.Fatalf("expected ErrInsufficientDepth, got %v", err)
+	}
+	if _, err := empty.VWAPSell(dec("1")); !errors.Is(err, ErrInsufficientDepth) {
+		t.Fatalf("expected ErrInsufficientDepth, got %v", err)
+	}
+}
@@ -1,64 +1,85 @@
 package bitrue
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"github.com/gorilla/websocket"
-	jsoniter "github.com/json-iterator/go"
-	"log"
+	"strings"
+	"sync"
 )
 
 //wss://ws.bitrue.com/kline-api/ws
 
-func SubDepthWs(symbol, address string) chan *DepthWs {
-	conn, _, err := websocket.DefaultDialer.Dial(address, nil)
-	if err != nil {
-		log.Println("websocket err:", err, symbol)
-	}
-	subMsg := `{"event":"sub","params":{"cb_id":"` + symbol + `","channel":"market_` + symbol + `_depth_step0"}}`
-	err = conn.WriteMessage(websocket.TextMessage, []byte(subMsg))
-	if err != nil {
-		log.Println(err)
-	}
+var (
+	sharedWsMu      sync.Mutex
+	sharedWsClients = make(map[string]*WsClient)
+	depthFeeds      = make(map[string]*depthFeed)
+)
 
-	_, message, err := conn.ReadMessage()
-	//log.Println(msgType)
+// depthFeed fans the depth of one symbol out to every SubDepthWs caller.
+type depthFeed struct {
+	mu   sync.Mutex
+	outs []chan *DepthWs
+}
 
-	unzipmsg, _ := ParseGzip(message)
-	log.Printf(string(unzipmsg))
-	ok := jsoniter.Get(unzipmsg, "status").ToString()
+func (feed *depthFeed) add() chan *DepthWs {
 	ch := make(chan *DepthWs, 10)
+	feed.mu.Lock()
+	feed.outs = append(feed.outs, ch)
+	feed.mu.Unlock()
+	return ch
+}
 
-	if ok == "ok" {
-		go func() {
-			for {
-				_, message, err = conn.ReadMessage()
-				unzipmsg, err := ParseGzip(message)
-				if err != nil {
-					log.Println(string(message), err)
-					continue
-				}
+func (feed *depthFeed) publish(depthWs *DepthWs) {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+	for _, ch := range feed.outs {
+		select {
+		case ch <- depthWs:
+		default:
+		}
+	}
+}
 
-				ms := TimestampNowMs()
-				//log.Printf(string(unzipmsg))
-				cmd := string(unzipmsg[2:6])
-				if cmd == "ping" {
-					pong := fmt.Sprintf("{\"pong\":%d}", ms)
-					conn.WriteMessage(websocket.TextMessage, []byte(pong))
-					continue
-				}
-				//log.Println(string(unzipmsg))
-				depthWs := &DepthWs{}
-				err = json.Unmarshal(unzipmsg, depthWs)
-				//data, _ := json.Marshal(depthWs)
-				//log.Println(string(data))
+// sharedWsClient returns the WsClient used by SubDepthWs for address, so
+// many symbols share the same connections. Must be called with sharedWsMu
+// held.
+func sharedWsClient(address string) *WsClient {
+	client, ok := sharedWsClients[address]
+	if !ok {
+		client = NewWsClient(context.Background(), WithWsURL(address))
+		sharedWsClients[address] = client
+	}
+	return client
+}
 
-				if err != nil {
-					log.Println(err)
-				}
-				ch <- depthWs
-			}
-		}()
+// SubDepthWs streams the depth of symbol over a connection shared by all
+// SubDepthWs calls for address; it is restored automatically when it drops.
+// Every call gets its own channel, a frame is dropped for a caller whose
+// channel is full, so a slow reader never stalls the connection. The frames
+// are shared between callers and must not be modified.
+//
+// The channel is never closed and the shared connection lives until the
+// process exits, use WsClient.SubscribeChan to control the lifetime.
+func SubDepthWs(symbol, address string) (chan *DepthWs, error) {
+	sharedWsMu.Lock()
+	defer sharedWsMu.Unlock()
+	channel := "market_" + strings.ToLower(symbol) + "_depth_step0"
+	key := address + " " + channel
+	if feed, ok := depthFeeds[key]; ok {
+		return feed.add(), nil
 	}
-	return ch
+	feed := &depthFeed{}
+	_, err := sharedWsClient(address).subscribeStream(channel, func(msg []byte) error {
+		depthWs := &DepthWs{}
+		if err := json.Unmarshal(msg, depthWs); err != nil {
+			return err
+		}
+		feed.publish(depthWs)
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	depthFeeds[key] = feed
+	return feed.add(), nil
 }
@@ -3,11 +3,75 @@ package bitrue
 import (
 	"testing"
 	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
 )
 
 func TestSubDepthWs(t *testing.T) {
-	SubDepthWs("btrusdt", "wss://ws.bitrue.com/kline-api/ws")
+	if _, err := SubDepthWs("btrusdt", "wss://ws.bitrue.com/kline-api/ws"); err != nil {
+		t.Fatal(err)
+	}
 	for {
 		time.Sleep(time.Second)
 	}
 }
+
+func TestSubDepthWsSharedConnection(t *testing.T) {
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		channels := make(chan string, 10)
+		go func() {
+			defer close(channels)
+			for {
+				_, msg, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				channels <- gjson.GetBytes(msg, "params.channel").String()
+			}
+		}()
+		// keep publishing every subscribed channel
+		var subscribed []string
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case channel, ok := <-channels:
+				if !ok {
+					return
+				}
+				subscribed = append(subscribed, channel)
+			case <-ticker.C:
+				for _, channel := range subscribed {
+					conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"channel":"`+channel+`","ts":1,"tick":{"asks":[[1.01,2]],"bids":[[0.99,3]]}}`))
+				}
+			}
+		}
+	})
+	defer server.Close()
+
+	// never drained, its frames are dropped instead of blocking the socket
+	if _, err := SubDepthWs("slowusdt", u); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	first, err := SubDepthWs("btrusdt", u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the symbol is case insensitive, both callers share one feed
+	second, err := SubDepthWs("BTRUSDT", u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range []chan *DepthWs{first, second} {
+		select {
+		case depthWs := <-ch:
+			if depthWs.Channel != "market_btrusdt_depth_step0" {
+				t.Fatalf("unexpected depth %+v", depthWs)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for depth")
+		}
+	}
+}
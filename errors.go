@@ -0,0 +1,78 @@
+package bitrue
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("bitrue: insufficient balance")
+	ErrUnknownOrder        = errors.New("bitrue: unknown order")
+	ErrInvalidSignature    = errors.New("bitrue: invalid signature")
+	ErrInvalidTimestamp    = errors.New("bitrue: timestamp outside recvWindow")
+	ErrInvalidAPIKey       = errors.New("bitrue: invalid api key")
+	ErrUnknownSymbol       = errors.New("bitrue: unknown symbol")
+	ErrTooManyRequests     = errors.New("bitrue: too many requests")
+	ErrUnknownAsset        = errors.New("bitrue: asset not found in account")
+	// ErrRateLimited is returned by fail fast clients instead of waiting
+	// for the local rate limiter.
+	ErrRateLimited = errors.New("bitrue: client side rate limit reached")
+)
+
+// APIError is returned when bitrue answers with a non-2xx status or a
+// {"code":..,"msg":..} payload.
+type APIError struct {
+	HTTPStatus int
+	Code       int
+	Msg        string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bitrue: api error (http %d, code %d): %s", e.HTTPStatus, e.Code, e.Msg)
+}
+
+// Is maps bitrue error codes onto the sentinel errors, so callers can use
+// errors.Is(err, ErrUnknownOrder) and friends.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInsufficientBalance:
+		return strings.Contains(strings.ToLower(e.Msg), "insufficient balance")
+	case ErrUnknownOrder:
+		return e.Code == -2011 || e.Code == -2013
+	case ErrInvalidSignature:
+		return e.Code == -1022
+	case ErrInvalidTimestamp:
+		return e.Code == -1021
+	case ErrInvalidAPIKey:
+		return e.Code == -2014 || e.Code == -2015
+	case ErrUnknownSymbol:
+		return e.Code == -1121
+	case ErrTooManyRequests:
+		return e.Code == -1003 || e.HTTPStatus == http.StatusTooManyRequests || e.HTTPStatus == http.StatusTeapot
+	}
+	return false
+}
+
+// checkResponse turns an error status or an error payload into an *APIError.
+func checkResponse(status int, body []byte) error {
+	code := gjson.GetBytes(body, "code")
+	msg := gjson.GetBytes(body, "msg")
+	if status >= 200 && status < 300 {
+		if code.Exists() && code.Int() != 0 && msg.Exists() {
+			return &APIError{HTTPStatus: status, Code: int(code.Int()), Msg: msg.String()}
+		}
+		return nil
+	}
+	apiErr := &APIError{HTTPStatus: status, Code: int(code.Int()), Msg: msg.String()}
+	if !msg.Exists() {
+		apiErr.Msg = strings.TrimSpace(string(body))
+		if apiErr.Msg == "" {
+			apiErr.Msg = http.StatusText(status)
+		}
+	}
+	return apiErr
+}
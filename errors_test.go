@@ -0,0 +1,35 @@
+package bitrue
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckResponse(t *testing.T) {
+	err := checkResponse(http.StatusBadRequest, []byte(`{"code":-2013,"msg":"Order does not exist."}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.HTTPStatus != http.StatusBadRequest || apiErr.Code != -2013 {
+		t.Fatalf("unexpected error fields: %+v", apiErr)
+	}
+	if !errors.Is(err, ErrUnknownOrder) || errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("sentinel mapping is wrong for %v", err)
+	}
+
+	err = checkResponse(http.StatusOK, []byte(`{"code":-2010,"msg":"Account has insufficient balance for requested action."}`))
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+
+	err = checkResponse(http.StatusTooManyRequests, []byte(``))
+	if !errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("expected ErrTooManyRequests, got %v", err)
+	}
+
+	if err := checkResponse(http.StatusOK, []byte(`{"symbol":"BTRUSDT","orderId":"1"}`)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -1,15 +1,16 @@
 package bitrue
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"github.com/spf13/cast"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,9 +20,153 @@ const (
 	DELETE = "DELETE"
 )
 
-func HttpGetRequest(strUrl string, mapParams map[string]string) string {
-	httpClient := &http.Client{}
+const (
+	defaultUserAgent = "Mozilla/5.0 (Windows NT 6.1; WOW64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36"
+	defaultTimeout   = 30 * time.Second
+)
+
+// Client owns the http connection pool, host and credentials used for
+// every REST call of an Exchange. Build it with NewClient and Options.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	UserAgent  string
+	// Timeout bounds every single request, 0 disables it.
+	Timeout time.Duration
+	// RecvWindow is sent with every signed request when > 0.
+	RecvWindow  time.Duration
+	RetryPolicy RetryPolicy
+	// RateLimitFailFast returns ErrRateLimited instead of waiting for the
+	// rate limiter.
+	RateLimitFailFast bool
+
+	apiKey    string
+	secretKey string
+	transport http.RoundTripper
+	limiter   *RateLimiter
+
+	timeMu     sync.RWMutex
+	timeOffset time.Duration
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying *http.Client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.HTTPClient = httpClient
+		}
+	}
+}
+
+// WithTransport sets the RoundTripper of the underlying *http.Client.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithBaseURL sets the rest host, e.g. https://www.bitrue.com
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if baseURL != "" {
+			c.BaseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithTimeout sets the per request timeout, 0 disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.Timeout = timeout
+	}
+}
+
+// WithRecvWindow sets the recvWindow of signed requests, bitrue rejects
+// requests whose timestamp is older than it.
+func WithRecvWindow(recvWindow time.Duration) Option {
+	return func(c *Client) {
+		c.RecvWindow = recvWindow
+	}
+}
+
+// WithRateLimiter shares a limiter between clients, nil disables limiting.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
 
+// WithRateLimitFailFast makes requests fail with ErrRateLimited instead of
+// blocking when the local rate limit is reached.
+func WithRateLimitFailFast() Option {
+	return func(c *Client) {
+		c.RateLimitFailFast = true
+	}
+}
+
+func NewClient(ak, sk string, opts ...Option) *Client {
+	c := &Client{
+		HTTPClient:  &http.Client{},
+		BaseURL:     https,
+		UserAgent:   defaultUserAgent,
+		Timeout:     defaultTimeout,
+		RetryPolicy: DefaultRetryPolicy,
+		apiKey:      ak,
+		secretKey:   sk,
+		limiter:     NewRateLimiter(nil),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.transport != nil {
+		httpClient := *c.HTTPClient
+		httpClient.Transport = c.transport
+		c.HTTPClient = &httpClient
+	}
+	return c
+}
+
+// defaultClient backs the package level request helpers.
+var defaultClient = NewClient("", "")
+
+// RateLimiter returns the limiter of the client, nil when disabled.
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.limiter
+}
+
+// SetRateLimits configures the limiter from the exchangeInfo rateLimits.
+func (c *Client) SetRateLimits(limits []RateLimit) {
+	if c.limiter != nil {
+		c.limiter.SetLimits(limits)
+	}
+}
+
+// Get sends a public request to path, e.g. /api/v1/depth
+func (c *Client) Get(ctx context.Context, path string, params map[string]string) (string, error) {
+	return c.getWithRetry(ctx, c.BaseURL+path, params, "")
+}
+
+// KeyedGet sends an unsigned request carrying the api key header, as
+// required by MARKET_DATA endpoints such as /api/v1/historicalTrades.
+func (c *Client) KeyedGet(ctx context.Context, path string, params map[string]string) (string, error) {
+	return c.getWithRetry(ctx, c.BaseURL+path, params, c.apiKey)
+}
+
+// Signed sends a signed request to path with the client credentials.
+func (c *Client) Signed(ctx context.Context, method string, path string, params map[string]string) (string, error) {
+	return c.signedWithRetry(ctx, method, c.BaseURL+path, params, c.apiKey, c.secretKey)
+}
+
+// get sends the api key header when ak is not empty.
+func (c *Client) get(ctx context.Context, strUrl string, mapParams map[string]string, ak string) (string, error) {
 	var strRequestUrl string
 	if nil == mapParams {
 		strRequestUrl = strUrl
@@ -33,57 +178,31 @@ func HttpGetRequest(strUrl string, mapParams map[string]string) string {
 	// 构建Request, 并且按官方要求添加Http Header
 	request, err := http.NewRequest("GET", strRequestUrl, nil)
 	if nil != err {
-		log.Println(strRequestUrl)
-		log.Println(request)
-		return err.Error()
+		return "", err
 	}
-	request.Header.Add("User-Agent", "Mozilla/5.0 (Windows NT 6.1; WOW64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/39.0.2171.71 Safari/537.36")
-
-	//	log.Println(strUrl)
-	//log.Println(request)
-	// 发出请求
-	response, err := httpClient.Do(request)
-	if nil != err {
-		log.Print(err)
-		return err.Error()
+	if ak != "" {
+		request.Header.Add("X-MBX-APIKEY", ak)
 	}
-	defer response.Body.Close()
-
-	// 解析响应内容
-	body, err := ioutil.ReadAll(response.Body)
-	if nil != err {
-		log.Print(err)
-		return err.Error()
-	}
-
-	return string(body)
+	weight, order := endpointWeight(GET, request.URL.Path, mapParams)
+	return c.do(ctx, request, weight, order)
 }
 
-// 将map格式的请求参数转换为字符串格式的
-// mapParams: map格式的参数键值对
-// return: 查询字符串
-func Map2UrlQuery(data map[string]string) string {
-	var strParams string
-	for key, value := range data {
-		strParams += key + "=" + value + "&"
+func (c *Client) signed(ctx context.Context, method string, url string, params map[string]string, ak, sk string) (string, error) {
+	if c.RecvWindow > 0 {
+		withWindow := make(map[string]string, len(params)+1)
+		for k, v := range params {
+			withWindow[k] = v
+		}
+		withWindow["recvWindow"] = cast.ToString(int64(c.RecvWindow / time.Millisecond))
+		params = withWindow
 	}
-	if 0 < len(strParams) {
-		strParams = string([]rune(strParams)[:len(strParams)-1])
-	}
-	return strParams
-}
-
-func SignedRequest(method string, url string, params map[string]string) string {
-	return SignedRequestWithKey(method, url, params, accessKey, secretKey)
-}
 
-func SignedRequestWithKey(method string, url string, params map[string]string, ak, sk string) string {
 	var paramStr string
 	if len(params) > 0 {
 		keys, values := SortByKey(params)
 		paramStr = Slice2UrlQuery(keys, values)
 	}
-	timestamp := cast.ToString(time.Now().UnixNano() / 1000000)
+	timestamp := cast.ToString(timeToMs(c.Now()))
 	if len(paramStr) > 0 {
 		paramStr += "&timestamp=" + timestamp
 	} else {
@@ -91,36 +210,93 @@ func SignedRequestWithKey(method string, url string, params map[string]string, a
 	}
 
 	signature := GetSignedWithSecretKey(paramStr, sk)
-	log.Println(signature)
 	paramStr += "&signature=" + signature
 
 	request, err := http.NewRequest(method, url, strings.NewReader(paramStr))
-
 	if err != nil {
-		log.Println(err)
-		return err.Error()
+		return "", err
 	}
 
 	request.Header.Add("X-MBX-APIKEY", ak)
-	//request.Header.Add("FC-ACCESS-TIMESTAMP", timestamp)
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	weight, order := endpointWeight(method, request.URL.Path, params)
+	return c.do(ctx, request, weight, order)
+}
 
-	httpClient := &http.Client{}
-	response, err := httpClient.Do(request)
+func (c *Client) do(ctx context.Context, request *http.Request, weight int, order bool) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.limiter != nil {
+		if c.RateLimitFailFast {
+			if !c.limiter.Allow(weight, order) {
+				return "", ErrRateLimited
+			}
+		} else if err := c.limiter.Wait(ctx, weight, order); err != nil {
+			return "", err
+		}
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	if c.UserAgent != "" {
+		request.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	// 发出请求
+	response, err := c.HTTPClient.Do(request.WithContext(ctx))
 	if nil != err {
-		log.Println("HTTP request error， url:", url, "params:", paramStr, "info:", err)
-		return ""
+		return "", err
 	}
 	defer response.Body.Close()
 
+	if c.limiter != nil && (response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusTeapot) {
+		c.limiter.Backoff(retryAfter(response.Header))
+	}
+
+	// 解析响应内容
 	body, err := ioutil.ReadAll(response.Body)
 	if nil != err {
-		log.Println("HTTP response data read error， url:", url, "params:", paramStr, "info:", err)
-		return err.Error()
+		return "", err
 	}
 
-	log.Println(response.Status)
-	return string(body)
+	return string(body), checkResponse(response.StatusCode, body)
+}
+
+func HttpGetRequest(strUrl string, mapParams map[string]string) (string, error) {
+	return HttpGetRequestCtx(context.Background(), strUrl, mapParams)
+}
+
+func HttpGetRequestCtx(ctx context.Context, strUrl string, mapParams map[string]string) (string, error) {
+	return defaultClient.getWithRetry(ctx, strUrl, mapParams, "")
+}
+
+// 将map格式的请求参数转换为字符串格式的
+// mapParams: map格式的参数键值对
+// return: 查询字符串
+func Map2UrlQuery(data map[string]string) string {
+	var strParams string
+	for key, value := range data {
+		strParams += key + "=" + value + "&"
+	}
+	if 0 < len(strParams) {
+		strParams = string([]rune(strParams)[:len(strParams)-1])
+	}
+	return strParams
+}
+
+func SignedRequest(method string, url string, params map[string]string) (string, error) {
+	return SignedRequestWithKey(method, url, params, accessKey, secretKey)
+}
+
+func SignedRequestWithKey(method string, url string, params map[string]string, ak, sk string) (string, error) {
+	return SignedRequestWithKeyCtx(context.Background(), method, url, params, ak, sk)
+}
+
+func SignedRequestWithKeyCtx(ctx context.Context, method string, url string, params map[string]string, ak, sk string) (string, error) {
+	return defaultClient.signedWithRetry(ctx, method, url, params, ak, sk)
 }
 
 func Slice2UrlQuery(keys []string, values []string) string {
@@ -0,0 +1,50 @@
+package bitrue
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/depth" || r.URL.Query().Get("symbol") != "BTRUSDT" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("unexpected user agent %q", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte(`{"lastUpdateId":1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("ak", "sk", WithBaseURL(server.URL), WithUserAgent("test-agent"))
+	body, err := client.Get(context.Background(), "/api/v1/depth", map[string]string{"symbol": "BTRUSDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != `{"lastUpdateId":1}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("ak", "sk", WithBaseURL(server.URL), WithTimeout(50*time.Millisecond), WithRetryPolicy(RetryPolicy{}))
+	start := time.Now()
+	if _, err := client.Get(context.Background(), "/api/v1/time", nil); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("timeout was not applied")
+	}
+}
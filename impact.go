@@ -0,0 +1,72 @@
+package bitrue
+
+import (
+	"fmt"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// MarketImpact estimates the execution of a market order against a Depth
+// snapshot.
+type MarketImpact struct {
+	// AvgPrice is the volume weighted price of the filled part.
+	AvgPrice *decimal.Big
+	// WorstPrice is the price of the last level the order reaches.
+	WorstPrice *decimal.Big
+	// Levels is the number of price levels consumed, partially or fully.
+	Levels int
+	// Filled is the base quantity bought or sold.
+	Filled *decimal.Big
+	// Amount is the quote amount spent or received.
+	Amount *decimal.Big
+	// SlippageBps is how much worse AvgPrice is than the mid price, in
+	// basis points. It is positive for both sides.
+	SlippageBps *decimal.Big
+	// TooThin is set when the book can not absorb the whole order, the
+	// other fields then describe the part that could be filled.
+	TooThin bool
+}
+
+// SimulateMarketBuy walks the asks with a market buy of quoteAmount, as
+// sent by BuyMarket with quoteOrderQty.
+func (depth *Depth) SimulateMarketBuy(quoteAmount *decimal.Big) (*MarketImpact, error) {
+	return depth.simulate(depth.Asks, quoteAmount, true)
+}
+
+// SimulateMarketSell walks the bids with a market sell of baseQty.
+func (depth *Depth) SimulateMarketSell(baseQty *decimal.Big) (*MarketImpact, error) {
+	return depth.simulate(depth.Bids, baseQty, false)
+}
+
+func (depth *Depth) simulate(levels [][2]*decimal.Big, size *decimal.Big, buy bool) (*MarketImpact, error) {
+	if err := checkSize(size); err != nil {
+		return nil, err
+	}
+	mid, err := depth.MidPrice()
+	if err != nil {
+		return nil, err
+	}
+	f := walkBook(levels, size, buy)
+	if f.base.Sign() == 0 {
+		return nil, fmt.Errorf("%w: nothing can be filled", ErrInsufficientDepth)
+	}
+	ctx := decimal.Context128
+	avg := ctx.Quo(new(decimal.Big), f.quote, f.base)
+	slippage := new(decimal.Big)
+	if buy {
+		ctx.Sub(slippage, avg, mid)
+	} else {
+		ctx.Sub(slippage, mid, avg)
+	}
+	ctx.Mul(slippage, slippage, bpsFactor)
+	ctx.Quo(slippage, slippage, mid)
+	return &MarketImpact{
+		AvgPrice:    avg,
+		WorstPrice:  f.worst,
+		Levels:      f.levels,
+		Filled:      f.base,
+		Amount:      f.quote,
+		SlippageBps: slippage,
+		TooThin:     !f.complete,
+	}, nil
+}
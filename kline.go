@@ -0,0 +1,157 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+const maxKlinesLimit = 1000
+
+// Interval is a kline/candlestick interval of the rest api.
+type Interval string
+
+const (
+	Interval1m  Interval = "1m"
+	Interval5m  Interval = "5m"
+	Interval15m Interval = "15m"
+	Interval30m Interval = "30m"
+	Interval1h  Interval = "1h"
+	Interval4h  Interval = "4h"
+	Interval1d  Interval = "1d"
+	Interval1w  Interval = "1w"
+	Interval1M  Interval = "1M"
+)
+
+// Duration of one candle, a month counts as 30 days.
+func (interval Interval) Duration() time.Duration {
+	switch interval {
+	case Interval1m:
+		return time.Minute
+	case Interval5m:
+		return 5 * time.Minute
+	case Interval15m:
+		return 15 * time.Minute
+	case Interval30m:
+		return 30 * time.Minute
+	case Interval1h:
+		return time.Hour
+	case Interval4h:
+		return 4 * time.Hour
+	case Interval1d:
+		return 24 * time.Hour
+	case Interval1w:
+		return 7 * 24 * time.Hour
+	case Interval1M:
+		return 30 * 24 * time.Hour
+	}
+	return 0
+}
+
+func (interval Interval) Valid() bool {
+	return interval.Duration() > 0
+}
+
+// GetKlines returns at most limit candles of [start, end], zero times and
+// limit are not sent.
+func (ex *Exchange) GetKlines(symbol string, interval Interval, start, end time.Time, limit int) ([]*KlineData, error) {
+	return ex.GetKlinesCtx(context.Background(), symbol, interval, start, end, limit)
+}
+
+func (ex *Exchange) GetKlinesCtx(ctx context.Context, symbol string, interval Interval, start, end time.Time, limit int) ([]*KlineData, error) {
+	if !interval.Valid() {
+		return nil, fmt.Errorf("bitrue: unknown kline interval %q", interval)
+	}
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	params["interval"] = string(interval)
+	if !start.IsZero() {
+		params["startTime"] = strconv.FormatInt(timeToMs(start), 10)
+	}
+	if !end.IsZero() {
+		params["endTime"] = strconv.FormatInt(timeToMs(end), 10)
+	}
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+	body, err := ex.client.Get(ctx, "/api/v1/klines", params)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]interface{}, 0)
+	err = json.Unmarshal([]byte(body), &rows)
+	if err != nil {
+		return nil, err
+	}
+	klines := make([]*KlineData, 0, len(rows))
+	for _, row := range rows {
+		kline, err := parseKlineRow(row)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
+
+// GetKlinesRange downloads every candle of [start, end] page by page.
+func (ex *Exchange) GetKlinesRange(symbol string, interval Interval, start, end time.Time) ([]*KlineData, error) {
+	return ex.GetKlinesRangeCtx(context.Background(), symbol, interval, start, end)
+}
+
+func (ex *Exchange) GetKlinesRangeCtx(ctx context.Context, symbol string, interval Interval, start, end time.Time) ([]*KlineData, error) {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	all := make([]*KlineData, 0)
+	for !start.After(end) {
+		klines, err := ex.GetKlinesCtx(ctx, symbol, interval, start, end, maxKlinesLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, kline := range klines {
+			if kline.Time().After(end) {
+				return all, nil
+			}
+			all = append(all, kline)
+		}
+		if len(klines) < maxKlinesLimit {
+			break
+		}
+		start = klines[len(klines)-1].Time().Add(interval.Duration())
+	}
+	return all, nil
+}
+
+// parseKlineRow reads [openTime, open, high, low, close, volume, closeTime,
+// quoteVolume, ...].
+func parseKlineRow(row []interface{}) (*KlineData, error) {
+	if len(row) < 8 {
+		return nil, fmt.Errorf("bitrue: unexpected kline row %v", row)
+	}
+	openTime, err := cast.ToInt64E(row[0])
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, 0, 6)
+	for _, i := range []int{1, 2, 3, 4, 5, 7} {
+		v, err := cast.ToFloat64E(row[i])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return &KlineData{
+		Id:     openTime / 1000,
+		Open:   values[0],
+		High:   values[1],
+		Low:    values[2],
+		Close:  values[3],
+		Amount: values[4],
+		Vol:    values[5],
+	}, nil
+}
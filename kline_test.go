@@ -0,0 +1,51 @@
+package bitrue
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetKlinesRange(t *testing.T) {
+	requests := 0
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		if q.Get("interval") != "1m" {
+			t.Errorf("unexpected interval %s", q.Get("interval"))
+		}
+		start, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+		end, _ := strconv.ParseInt(q.Get("endTime"), 10, 64)
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		rows := make([]string, 0)
+		for ts := start; ts <= end && len(rows) < limit; ts += 60000 {
+			rows = append(rows, fmt.Sprintf(`[%d,"1.0","2.0","0.5","1.5","10",%d,"15",3,"5","7","0"]`, ts, ts+59999))
+		}
+		w.Write([]byte("[" + strings.Join(rows, ",") + "]"))
+	})
+	defer server.Close()
+
+	start := time.Unix(1500000000, 0)
+	end := start.Add(2500 * time.Minute)
+	klines, err := ex.GetKlinesRange("BTRUSDT", Interval1m, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(klines) != 2501 || requests != 3 {
+		t.Fatalf("expected 2501 candles in 3 requests, got %d in %d", len(klines), requests)
+	}
+	first, last := klines[0], klines[len(klines)-1]
+	if !first.Time().Equal(start) || !last.Time().Equal(end) {
+		t.Fatalf("unexpected range %v - %v", first.Time(), last.Time())
+	}
+	if first.Open != 1 || first.High != 2 || first.Low != 0.5 || first.Close != 1.5 || first.Amount != 10 || first.Vol != 15 {
+		t.Fatalf("unexpected candle %+v", first)
+	}
+
+	if _, err := ex.GetKlines("BTRUSDT", Interval("7m"), start, end, 10); err == nil {
+		t.Fatal("expected error for unknown interval")
+	}
+}
@@ -0,0 +1,87 @@
+package bitrue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// wsName is the interval as used in kline channel names.
+func (interval Interval) wsName() string {
+	switch interval {
+	case Interval1m:
+		return "1min"
+	case Interval5m:
+		return "5min"
+	case Interval15m:
+		return "15min"
+	case Interval30m:
+		return "30min"
+	case Interval1h:
+		return "60min"
+	case Interval4h:
+		return "4h"
+	case Interval1d:
+		return "1day"
+	case Interval1w:
+		return "1week"
+	case Interval1M:
+		return "1month"
+	}
+	return ""
+}
+
+func klineChannel(symbol string, interval Interval) string {
+	return "market_" + strings.ToLower(symbol) + "_kline_" + interval.wsName()
+}
+
+// KlineUpdate is a candle of a kline stream. Updates of the open candle have
+// Closed unset; when a frame of the next candle arrives, the last state of
+// the previous one is sent again with Closed set.
+type KlineUpdate struct {
+	KlineData
+	Symbol   string
+	Interval Interval
+	Closed   bool
+}
+
+// KlineStream delivers the candles of one symbol and interval.
+type KlineStream struct {
+	Symbol   string
+	Interval Interval
+	C        <-chan *KlineUpdate
+
+	wsStream
+}
+
+// SubscribeKline streams the candles of symbol.
+func (c *WsClient) SubscribeKline(symbol string, interval Interval) (*KlineStream, error) {
+	if !interval.Valid() {
+		return nil, fmt.Errorf("bitrue: unknown kline interval %q", interval)
+	}
+	out := make(chan *KlineUpdate, 1)
+	var last *KlineData
+	stream, err := c.subscribeStream(klineChannel(symbol, interval), func(msg []byte) error {
+		kline := &Kline{}
+		if err := json.Unmarshal(msg, kline); err != nil {
+			return err
+		}
+		data := kline.Data
+		if last != nil {
+			if data.Id < last.Id {
+				// late frame of a candle already closed
+				return nil
+			}
+			if data.Id > last.Id {
+				out <- &KlineUpdate{KlineData: *last, Symbol: symbol, Interval: interval, Closed: true}
+			}
+		}
+		last = &data
+		out <- &KlineUpdate{KlineData: data, Symbol: symbol, Interval: interval}
+		return nil
+	}, func() { close(out) })
+	if err != nil {
+		return nil, err
+	}
+	return &KlineStream{Symbol: symbol, Interval: interval, C: out, wsStream: stream}, nil
+}
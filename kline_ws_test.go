@@ -0,0 +1,67 @@
+package bitrue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+func TestSubscribeKline(t *testing.T) {
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		channel := gjson.GetBytes(msg, "params.channel").String()
+		if channel != "market_btrusdt_kline_60min" {
+			t.Errorf("unexpected channel %s", channel)
+		}
+		for _, tick := range []string{
+			`{"id":3600,"open":1,"close":1.1,"high":1.2,"low":0.9,"amount":5,"vol":5.5}`,
+			`{"id":3600,"open":1,"close":1.3,"high":1.3,"low":0.9,"amount":7,"vol":8}`,
+			`{"id":7200,"open":1.3,"close":1.3,"high":1.3,"low":1.3,"amount":1,"vol":1.3}`,
+			`{"id":3600,"open":1,"close":1.4,"high":1.4,"low":0.9,"amount":8,"vol":9}`,
+		} {
+			conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"channel":"`+channel+`","ts":1,"tick":`+tick+`}`))
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(fastWsReconnect))
+	if _, err := client.SubscribeKline("BTRUSDT", Interval("2m")); err == nil {
+		t.Fatal("expected interval error")
+	}
+	stream, err := client.SubscribeKline("BTRUSDT", Interval1h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		id     int64
+		close  float64
+		closed bool
+	}{{3600, 1.1, false}, {3600, 1.3, false}, {3600, 1.3, true}, {7200, 1.3, false}}
+	for _, w := range want {
+		select {
+		case kline := <-stream.C:
+			if kline.Id != w.id || kline.Close != w.close || kline.Closed != w.closed || kline.Interval != Interval1h {
+				t.Fatalf("unexpected kline %+v, want %+v", kline, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for kline")
+		}
+	}
+
+	client.Close()
+	for kline := range stream.C {
+		t.Fatalf("unexpected kline after close %+v", kline)
+	}
+}
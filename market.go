@@ -5,6 +5,7 @@ import (
 	"github.com/ericlagergren/decimal"
 	"github.com/spf13/cast"
 	"log"
+	"time"
 )
 
 var https = "https://www.bitrue.com"
@@ -22,8 +23,157 @@ type SymbolData struct {
 	QuotePrecision int
 	BaseAsset      string
 	QuoteAsset     string
+	OrderTypes     []string
+	IcebergAllowed bool
+	Filters        []*SymbolFilter
+}
+
+const (
+	FilterPrice         = "PRICE_FILTER"
+	FilterPercentPrice  = "PERCENT_PRICE"
+	FilterLotSize       = "LOT_SIZE"
+	FilterMarketLotSize = "MARKET_LOT_SIZE"
+	FilterMinNotional   = "MIN_NOTIONAL"
+	FilterIcebergParts  = "ICEBERG_PARTS"
+	FilterMaxNumOrders  = "MAX_NUM_ORDERS"
+	FilterMaxAlgoOrders = "MAX_NUM_ALGO_ORDERS"
+)
+
+// SymbolFilter is one entry of the filters array of a symbol, only the
+// fields of its FilterType are set. Bitrue sends priceScale/volumeScale
+// and a minVal in LOT_SIZE instead of tickSize/stepSize/MIN_NOTIONAL for
+// some symbols, the accessors of SymbolData handle both forms.
+type SymbolFilter struct {
+	FilterType string
+
+	// PRICE_FILTER
+	MinPrice   *decimal.Big
+	MaxPrice   *decimal.Big
+	TickSize   *decimal.Big
+	PriceScale *int
+
+	// PERCENT_PRICE
+	MultiplierUp   *decimal.Big
+	MultiplierDown *decimal.Big
+	AvgPriceMins   int
+
+	// LOT_SIZE, MARKET_LOT_SIZE
+	MinQty      *decimal.Big
+	MaxQty      *decimal.Big
+	StepSize    *decimal.Big
+	VolumeScale *int
+	MinVal      *decimal.Big
+
+	// MIN_NOTIONAL
+	MinNotional   *decimal.Big
+	ApplyToMarket bool
+
+	// ICEBERG_PARTS
+	Limit int
+
+	// MAX_NUM_ORDERS, MAX_NUM_ALGO_ORDERS
+	MaxNumOrders     int
+	MaxNumAlgoOrders int
+}
+
+type PriceFilter struct {
+	MinPrice *decimal.Big
+	MaxPrice *decimal.Big
+	TickSize *decimal.Big
+}
+
+type LotSizeFilter struct {
+	MinQty   *decimal.Big
+	MaxQty   *decimal.Big
+	StepSize *decimal.Big
+}
+
+func (symbolData *SymbolData) GetFilter(filterType string) *SymbolFilter {
+	for _, filter := range symbolData.Filters {
+		if filter.FilterType == filterType {
+			return filter
+		}
+	}
+	return nil
+}
+
+// PriceFilter returns nil if the symbol has no PRICE_FILTER.
+func (symbolData *SymbolData) PriceFilter() *PriceFilter {
+	filter := symbolData.GetFilter(FilterPrice)
+	if filter == nil {
+		return nil
+	}
+	priceFilter := &PriceFilter{
+		MinPrice: filter.MinPrice,
+		MaxPrice: filter.MaxPrice,
+		TickSize: filter.TickSize,
+	}
+	if isZero(priceFilter.TickSize) && filter.PriceScale != nil {
+		priceFilter.TickSize = scaleStep(*filter.PriceScale)
+	}
+	return priceFilter
+}
+
+// LotSizeFilter returns nil if the symbol has no LOT_SIZE.
+func (symbolData *SymbolData) LotSizeFilter() *LotSizeFilter {
+	return symbolData.lotSize(FilterLotSize)
+}
+
+// MarketLotSizeFilter falls back to LOT_SIZE if there is no MARKET_LOT_SIZE.
+func (symbolData *SymbolData) MarketLotSizeFilter() *LotSizeFilter {
+	if lotSize := symbolData.lotSize(FilterMarketLotSize); lotSize != nil {
+		return lotSize
+	}
+	return symbolData.LotSizeFilter()
+}
+
+func (symbolData *SymbolData) lotSize(filterType string) *LotSizeFilter {
+	filter := symbolData.GetFilter(filterType)
+	if filter == nil {
+		return nil
+	}
+	lotSize := &LotSizeFilter{
+		MinQty:   filter.MinQty,
+		MaxQty:   filter.MaxQty,
+		StepSize: filter.StepSize,
+	}
+	if isZero(lotSize.StepSize) && filter.VolumeScale != nil {
+		lotSize.StepSize = scaleStep(*filter.VolumeScale)
+	}
+	return lotSize
+}
+
+// MinNotional is the minimum price * quantity of an order, nil if unknown.
+func (symbolData *SymbolData) MinNotional() *decimal.Big {
+	if filter := symbolData.GetFilter(FilterMinNotional); filter != nil && !isZero(filter.MinNotional) {
+		return filter.MinNotional
+	}
+	if filter := symbolData.GetFilter(FilterLotSize); filter != nil && !isZero(filter.MinVal) {
+		return filter.MinVal
+	}
+	return nil
 }
 
+func (symbolData *SymbolData) SupportsOrderType(orderType string) bool {
+	for _, t := range symbolData.OrderTypes {
+		if t == orderType {
+			return true
+		}
+	}
+	return false
+}
+
+func isZero(d *decimal.Big) bool {
+	return d == nil || d.Sign() == 0
+}
+
+// scaleStep returns 10^-scale
+func scaleStep(scale int) *decimal.Big {
+	return new(decimal.Big).SetMantScale(1, scale)
+}
+
+// KlineData is a candle, Id is its open time in seconds, Amount the base
+// volume and Vol the quote volume.
 type KlineData struct {
 	Id     int64
 	Amount float64
@@ -34,6 +184,10 @@ type KlineData struct {
 	Open   float64
 }
 
+func (kline *KlineData) Time() time.Time {
+	return time.Unix(kline.Id, 0)
+}
+
 type ReqKline struct {
 	EventRep string `json:"event_rep"`
 	Symbol   string `json:"cb_id"`
@@ -59,13 +213,26 @@ type DepthData struct {
 
 type Trade struct {
 	Id           int64
-	Price        string
-	Qty          string
+	Price        *decimal.Big
+	Qty          *decimal.Big
 	Time         int64
 	IsBuyerMaker bool
 	IsBestMatch  bool
 }
 
+// AggTrade is a compressed trade, trades of one taker order at one price
+// are aggregated.
+type AggTrade struct {
+	Id           int64        `json:"a"`
+	Price        *decimal.Big `json:"p"`
+	Qty          *decimal.Big `json:"q"`
+	FirstTradeId int64        `json:"f"`
+	LastTradeId  int64        `json:"l"`
+	Time         int64        `json:"T"`
+	IsBuyerMaker bool         `json:"m"`
+	IsBestMatch  bool         `json:"M"`
+}
+
 func (depthData *DepthData) UnmarshalJSON(data []byte) error {
 	dep := struct {
 		Bids [][2]*decimal.Big `json:"buys"`
@@ -104,38 +271,57 @@ type Depth struct {
 	Asks         [][2]*decimal.Big
 }
 
-func (depth *Depth) Section() float64 {
-	askPrice1, _ := depth.Asks[0][0].Float64()
-	bidPrice1, _ := depth.Bids[0][0].Float64()
-	return askPrice1 - bidPrice1
+// Section is the best ask minus the best bid.
+func (depth *Depth) Section() (float64, error) {
+	askPrice1, err := depth.AsksPrice(0)
+	if err != nil {
+		return 0, err
+	}
+	bidPrice1, err := depth.BidsPrice(0)
+	if err != nil {
+		return 0, err
+	}
+	return askPrice1 - bidPrice1, nil
 }
 
-func (depth *Depth) BidsPrice(row int) float64 {
-	price, _ := depth.Bids[row][0].Float64()
-	return price
+func (depth *Depth) BidsPrice(row int) (float64, error) {
+	level, err := depthLevel(depth.Bids, row)
+	if err != nil {
+		return 0, err
+	}
+	price, _ := level[0].Float64()
+	return price, nil
 }
 
-func (depth *Depth) AsksPrice(row int) float64 {
-	price, _ := depth.Asks[row][0].Float64()
-	return price
+func (depth *Depth) AsksPrice(row int) (float64, error) {
+	level, err := depthLevel(depth.Asks, row)
+	if err != nil {
+		return 0, err
+	}
+	price, _ := level[0].Float64()
+	return price, nil
 }
 
-func (depth *Depth) DepthBidsAmountAll(row int) float64 {
-	amount := 0.0
-	for i := 0; i <= row; i++ {
-		a, _ := depth.Bids[i][1].Float64()
-		amount += a
-	}
-	return amount
+// DepthBidsAmountAll sums the bid quantity of rows 0 to row inclusive.
+func (depth *Depth) DepthBidsAmountAll(row int) (float64, error) {
+	return depthAmountAll(depth.Bids, row)
+}
+
+// DepthAsksAmountAll sums the ask quantity of rows 0 to row inclusive.
+func (depth *Depth) DepthAsksAmountAll(row int) (float64, error) {
+	return depthAmountAll(depth.Asks, row)
 }
 
-func (depth *Depth) DepthAsksAmountAll(row int) float64 {
+func depthAmountAll(levels [][2]*decimal.Big, row int) (float64, error) {
+	if _, err := depthLevel(levels, row); err != nil {
+		return 0, err
+	}
 	amount := 0.0
 	for i := 0; i <= row; i++ {
-		a, _ := depth.Asks[i][1].Float64()
+		a, _ := levels[i][1].Float64()
 		amount += a
 	}
-	return amount
+	return amount, nil
 }
 
 type PriceTicker struct {
@@ -162,16 +348,17 @@ func (bookTicker *BookTicker) GetBuyPrice() float64 {
 }
 
 type OrderData struct {
-	Symbol      string      `json:"symbol"`
-	OrderId     int64       `json:",string"`
-	Price       decimal.Big `json:"price"`
-	OrigQty     decimal.Big `json:"origQty"`
-	ExecutedQty string      `json:"executedQty"`
-	Side        string
-	Type        string
-	Status      string
-	Time        int64 `json:"time"`
-	UpdateTime  int64 `json:"updateTime"`
+	Symbol        string      `json:"symbol"`
+	OrderId       int64       `json:",string"`
+	ClientOrderId string      `json:"clientOrderId"`
+	Price         decimal.Big `json:"price"`
+	OrigQty       decimal.Big `json:"origQty"`
+	ExecutedQty   string      `json:"executedQty"`
+	Side          string
+	Type          string
+	Status        string
+	Time          int64 `json:"time"`
+	UpdateTime    int64 `json:"updateTime"`
 }
 
 func (order *OrderData) Filled() float64 {
@@ -197,6 +384,15 @@ func (order *OrderData) IsFilled() bool {
 	return order.Status == "FILLED"
 }
 
+// IsFinal reports whether the order can not change any more.
+func (order *OrderData) IsFinal() bool {
+	switch order.Status {
+	case "FILLED", "CANCELED", "REJECTED", "EXPIRED":
+		return true
+	}
+	return false
+}
+
 func (order *OrderData) FilledAmount() float64 {
 	filleAmount := cast.ToFloat64(order.ExecutedQty)
 	return filleAmount
@@ -242,6 +438,7 @@ type Balance struct {
 }
 
 type DeleteReturn struct {
-	Symbol  string
-	OrderId int64
+	Symbol        string
+	OrderId       int64
+	ClientOrderId string
 }
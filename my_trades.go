@@ -0,0 +1,175 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/ericlagergren/decimal"
+)
+
+const maxMyTradesLimit = 1000
+
+// MyTrade is a fill of the account.
+type MyTrade struct {
+	Symbol          string       `json:"symbol"`
+	Id              int64        `json:"id"`
+	OrderId         int64        `json:",string"`
+	Price           *decimal.Big `json:"price"`
+	Qty             *decimal.Big `json:"qty"`
+	Commission      *decimal.Big `json:"commission"`
+	CommissionAsset string       `json:"commissionAsset"`
+	Time            int64        `json:"time"`
+	IsBuyer         bool         `json:"isBuyer"`
+	IsMaker         bool         `json:"isMaker"`
+	IsBestMatch     bool         `json:"isBestMatch"`
+}
+
+// QuoteQty is price * qty.
+func (trade *MyTrade) QuoteQty() *decimal.Big {
+	return decimal.Context128.Mul(new(decimal.Big), trade.Price, trade.Qty)
+}
+
+// MyTradesRequest filters GET /api/v1/myTrades, zero fields are not sent.
+type MyTradesRequest struct {
+	Symbol    string
+	OrderId   int64
+	FromId    int64
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+}
+
+func (req MyTradesRequest) params() map[string]string {
+	params := make(map[string]string)
+	params["symbol"] = req.Symbol
+	if req.OrderId > 0 {
+		params["orderId"] = strconv.FormatInt(req.OrderId, 10)
+	}
+	if req.FromId > 0 {
+		params["fromId"] = strconv.FormatInt(req.FromId, 10)
+	}
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(timeToMs(req.StartTime), 10)
+	}
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(timeToMs(req.EndTime), 10)
+	}
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+	return params
+}
+
+func (ex *Exchange) MyTrades(req MyTradesRequest) ([]*MyTrade, error) {
+	return ex.MyTradesCtx(context.Background(), req)
+}
+
+func (ex *Exchange) MyTradesCtx(ctx context.Context, req MyTradesRequest) ([]*MyTrade, error) {
+	return ex.myTrades(ctx, req.params())
+}
+
+func (ex *Exchange) myTrades(ctx context.Context, params map[string]string) ([]*MyTrade, error) {
+	body, err := ex.client.Signed(ctx, GET, "/api/v1/myTrades", params)
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]*MyTrade, 0)
+	err = json.Unmarshal([]byte(body), &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// MyTradesIterator walks the trade history page by page in id order, every
+// page goes through the rate limiter of the client.
+//
+//	it := ex.NewMyTradesIterator(bitrue.MyTradesRequest{Symbol: "BTRUSDT"})
+//	for it.Next(ctx) {
+//		for _, trade := range it.Trades() { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type MyTradesIterator struct {
+	ex     *Exchange
+	req    MyTradesRequest
+	trades []*MyTrade
+	done   bool
+	err    error
+}
+
+// NewMyTradesIterator starts at req.FromId, or at req.StartTime, or at the
+// first trade when neither is set, and stops after req.EndTime when it is
+// set.
+func (ex *Exchange) NewMyTradesIterator(req MyTradesRequest) *MyTradesIterator {
+	if req.Limit <= 0 || req.Limit > maxMyTradesLimit {
+		req.Limit = maxMyTradesLimit
+	}
+	return &MyTradesIterator{ex: ex, req: req}
+}
+
+// Next fetches the next page, it returns false at the end or on error.
+func (it *MyTradesIterator) Next(ctx context.Context) bool {
+	if it.done {
+		it.trades = nil
+		return false
+	}
+	req := it.req
+	if req.FromId > 0 {
+		// fromId and a time range are exclusive, EndTime is applied below
+		req.StartTime, req.EndTime = time.Time{}, time.Time{}
+	}
+	params := req.params()
+	if req.FromId == 0 && req.StartTime.IsZero() {
+		// without fromId bitrue returns the most recent trades, start at
+		// the oldest one instead
+		params["fromId"] = "0"
+	}
+	trades, err := it.ex.myTrades(ctx, params)
+	if err != nil {
+		it.err = err
+		it.done = true
+		it.trades = nil
+		return false
+	}
+	if len(trades) < req.Limit {
+		it.done = true
+	}
+	if !it.req.EndTime.IsZero() {
+		end := timeToMs(it.req.EndTime)
+		for i, trade := range trades {
+			if trade.Time > end {
+				trades = trades[:i]
+				it.done = true
+				break
+			}
+		}
+	}
+	if len(trades) == 0 {
+		it.done = true
+		it.trades = nil
+		return false
+	}
+	it.req.FromId = trades[len(trades)-1].Id + 1
+	it.trades = trades
+	return true
+}
+
+// Trades is the current page.
+func (it *MyTradesIterator) Trades() []*MyTrade {
+	return it.trades
+}
+
+func (it *MyTradesIterator) Err() error {
+	return it.err
+}
+
+// GroupTradesByOrder maps orderId to its fills, to reconcile OrderData.
+func GroupTradesByOrder(trades []*MyTrade) map[int64][]*MyTrade {
+	byOrder := make(map[int64][]*MyTrade)
+	for _, trade := range trades {
+		byOrder[trade.OrderId] = append(byOrder[trade.OrderId], trade)
+	}
+	return byOrder
+}
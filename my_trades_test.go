@@ -0,0 +1,54 @@
+package bitrue
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMyTradesIterator(t *testing.T) {
+	requests := 0
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := ioutil.ReadAll(r.Body)
+		params, _ := url.ParseQuery(string(body))
+		fromId, _ := strconv.Atoi(params.Get("fromId"))
+		limit, _ := strconv.Atoi(params.Get("limit"))
+		if _, ok := params["fromId"]; !ok {
+			// like bitrue, return the most recent page
+			fromId = 5 - limit + 1
+		}
+		if fromId == 0 {
+			fromId = 1
+		}
+		trades := make([]string, 0)
+		for id := fromId; id < fromId+limit && id <= 5; id++ {
+			trades = append(trades, fmt.Sprintf(`{"symbol":"BTRUSDT","id":%d,"orderId":"%d","price":"0.1","qty":"10","commission":"0.01","commissionAsset":"USDT","time":%d,"isMaker":true}`, id, id%2, id*1000))
+		}
+		w.Write([]byte("[" + strings.Join(trades, ",") + "]"))
+	})
+	defer server.Close()
+
+	it := ex.NewMyTradesIterator(MyTradesRequest{Symbol: "BTRUSDT", Limit: 2})
+	var all []*MyTrade
+	for it.Next(context.Background()) {
+		all = append(all, it.Trades()...)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 5 || all[4].Id != 5 || requests != 3 {
+		t.Fatalf("expected 5 trades in 3 pages, got %d trades in %d requests", len(all), requests)
+	}
+	if !all[0].IsMaker || all[0].QuoteQty().Cmp(dec("1")) != 0 {
+		t.Fatalf("unexpected trade %+v", all[0])
+	}
+	if byOrder := GroupTradesByOrder(all); len(byOrder[1]) != 3 || len(byOrder[0]) != 2 {
+		t.Fatalf("unexpected grouping %v", byOrder)
+	}
+}
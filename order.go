@@ -0,0 +1,426 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ericlagergren/decimal"
+)
+
+const (
+	SideBuy  = "BUY"
+	SideSell = "SELL"
+)
+
+const (
+	OrderTypeLimit           = "LIMIT"
+	OrderTypeMarket          = "MARKET"
+	OrderTypeLimitMaker      = "LIMIT_MAKER"
+	OrderTypeStopLoss        = "STOP_LOSS"
+	OrderTypeStopLossLimit   = "STOP_LOSS_LIMIT"
+	OrderTypeTakeProfit      = "TAKE_PROFIT"
+	OrderTypeTakeProfitLimit = "TAKE_PROFIT_LIMIT"
+)
+
+const (
+	TimeInForceGTC = "GTC"
+	TimeInForceIOC = "IOC"
+	TimeInForceFOK = "FOK"
+)
+
+const (
+	OrderRespTypeAck    = "ACK"
+	OrderRespTypeResult = "RESULT"
+	OrderRespTypeFull   = "FULL"
+)
+
+const SymbolStatusTrading = "TRADING"
+
+var (
+	// ErrFilterViolation matches every *FilterError with errors.Is.
+	ErrFilterViolation = errors.New("bitrue: order violates symbol filter")
+	// ErrInvalidOrderRequest is wrapped when a required field is missing.
+	ErrInvalidOrderRequest = errors.New("bitrue: invalid order request")
+)
+
+// OrderRequest describes a new order of any type, nil and empty fields are
+// not sent. Prices and quantities are sent exactly as given, see RoundPrice
+// and RoundQuantity.
+type OrderRequest struct {
+	Symbol      string
+	Side        string
+	Type        string
+	TimeInForce string
+	Price       *decimal.Big
+	Quantity    *decimal.Big
+	// QuoteOrderQty sizes a MARKET order in quote asset instead of Quantity.
+	QuoteOrderQty    *decimal.Big
+	StopPrice        *decimal.Big
+	IcebergQty       *decimal.Big
+	NewClientOrderID string
+	// NewOrderRespType FULL returns the fills of the order in OrderAck.
+	NewOrderRespType string
+}
+
+func NewLimitOrder(symbol, side string, price, quantity *decimal.Big) OrderRequest {
+	return OrderRequest{Symbol: symbol, Side: side, Type: OrderTypeLimit, Price: price, Quantity: quantity}
+}
+
+// NewLimitMakerOrder is a post only order, rejected if it would match at once.
+func NewLimitMakerOrder(symbol, side string, price, quantity *decimal.Big) OrderRequest {
+	return OrderRequest{Symbol: symbol, Side: side, Type: OrderTypeLimitMaker, Price: price, Quantity: quantity}
+}
+
+func NewMarketOrder(symbol, side string, quantity *decimal.Big) OrderRequest {
+	return OrderRequest{Symbol: symbol, Side: side, Type: OrderTypeMarket, Quantity: quantity}
+}
+
+// NewMarketQuoteOrder spends (BUY) or receives (SELL) quoteOrderQty of the
+// quote asset.
+func NewMarketQuoteOrder(symbol, side string, quoteOrderQty *decimal.Big) OrderRequest {
+	return OrderRequest{Symbol: symbol, Side: side, Type: OrderTypeMarket, QuoteOrderQty: quoteOrderQty}
+}
+
+func NewStopLossLimitOrder(symbol, side string, stopPrice, price, quantity *decimal.Big) OrderRequest {
+	return OrderRequest{
+		Symbol:      symbol,
+		Side:        side,
+		Type:        OrderTypeStopLossLimit,
+		TimeInForce: TimeInForceGTC,
+		StopPrice:   stopPrice,
+		Price:       price,
+		Quantity:    quantity,
+	}
+}
+
+func NewTakeProfitLimitOrder(symbol, side string, stopPrice, price, quantity *decimal.Big) OrderRequest {
+	return OrderRequest{
+		Symbol:      symbol,
+		Side:        side,
+		Type:        OrderTypeTakeProfitLimit,
+		TimeInForce: TimeInForceGTC,
+		StopPrice:   stopPrice,
+		Price:       price,
+		Quantity:    quantity,
+	}
+}
+
+// isMarketType reports whether the order executes without a limit price.
+func isMarketType(orderType string) bool {
+	switch strings.ToUpper(orderType) {
+	case OrderTypeMarket, OrderTypeStopLoss, OrderTypeTakeProfit:
+		return true
+	}
+	return false
+}
+
+// check verifies the fields required by the order type.
+func (req OrderRequest) check() error {
+	if req.Symbol == "" {
+		return fmt.Errorf("%w: symbol is required", ErrInvalidOrderRequest)
+	}
+	switch strings.ToUpper(req.Side) {
+	case SideBuy, SideSell:
+	default:
+		return fmt.Errorf("%w: side must be BUY or SELL, got %q", ErrInvalidOrderRequest, req.Side)
+	}
+
+	orderType := strings.ToUpper(req.Type)
+	switch orderType {
+	case OrderTypeLimit, OrderTypeMarket, OrderTypeLimitMaker:
+	case OrderTypeStopLoss, OrderTypeStopLossLimit, OrderTypeTakeProfit, OrderTypeTakeProfitLimit:
+		if req.StopPrice == nil {
+			return fmt.Errorf("%w: %s order requires stopPrice", ErrInvalidOrderRequest, orderType)
+		}
+	default:
+		return fmt.Errorf("%w: unknown order type %q", ErrInvalidOrderRequest, req.Type)
+	}
+	if isMarketType(orderType) {
+		if req.Quantity == nil && req.QuoteOrderQty == nil {
+			return fmt.Errorf("%w: %s order requires quantity or quoteOrderQty", ErrInvalidOrderRequest, orderType)
+		}
+	} else if req.Price == nil || req.Quantity == nil {
+		return fmt.Errorf("%w: %s order requires price and quantity", ErrInvalidOrderRequest, orderType)
+	}
+
+	switch strings.ToUpper(req.TimeInForce) {
+	case "", TimeInForceGTC, TimeInForceIOC, TimeInForceFOK:
+	default:
+		return fmt.Errorf("%w: unknown timeInForce %q", ErrInvalidOrderRequest, req.TimeInForce)
+	}
+	return nil
+}
+
+func (req OrderRequest) params() map[string]string {
+	params := make(map[string]string)
+	params["symbol"] = req.Symbol
+	params["side"] = strings.ToUpper(req.Side)
+	params["type"] = strings.ToUpper(req.Type)
+	if req.TimeInForce != "" {
+		params["timeInForce"] = strings.ToUpper(req.TimeInForce)
+	}
+	if req.Price != nil {
+		params["price"] = FormatDecimal(req.Price)
+	}
+	if req.Quantity != nil {
+		params["quantity"] = FormatDecimal(req.Quantity)
+	}
+	if req.QuoteOrderQty != nil {
+		params["quoteOrderQty"] = FormatDecimal(req.QuoteOrderQty)
+	}
+	if req.StopPrice != nil {
+		params["stopPrice"] = FormatDecimal(req.StopPrice)
+	}
+	if req.IcebergQty != nil {
+		params["icebergQty"] = FormatDecimal(req.IcebergQty)
+	}
+	if req.NewClientOrderID != "" {
+		params["newClientOrderId"] = req.NewClientOrderID
+	}
+	if req.NewOrderRespType != "" {
+		params["newOrderRespType"] = strings.ToUpper(req.NewOrderRespType)
+	}
+	return params
+}
+
+// OrderAck is the response of a new order.
+type OrderAck struct {
+	Symbol              string       `json:"symbol"`
+	OrderId             int64        `json:"orderId"`
+	ClientOrderId       string       `json:"clientOrderId"`
+	TransactTime        int64        `json:"transactTime"`
+	Price               *decimal.Big `json:"price"`
+	OrigQty             *decimal.Big `json:"origQty"`
+	ExecutedQty         *decimal.Big `json:"executedQty"`
+	CummulativeQuoteQty *decimal.Big `json:"cummulativeQuoteQty"`
+	Status              string       `json:"status"`
+	TimeInForce         string       `json:"timeInForce"`
+	Type                string       `json:"type"`
+	Side                string       `json:"side"`
+	// Fills is only set for NewOrderRespType FULL.
+	Fills []*Fill `json:"fills"`
+}
+
+// UnmarshalJSON accepts orderId as a string or a number, a failure here
+// would report an error for an order that was accepted.
+func (ack *OrderAck) UnmarshalJSON(data []byte) error {
+	type orderAck OrderAck
+	aux := struct {
+		*orderAck
+		OrderId json.RawMessage `json:"orderId"`
+	}{orderAck: (*orderAck)(ack)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.OrderId) == 0 || string(aux.OrderId) == "null" {
+		return nil
+	}
+	orderId, err := strconv.ParseInt(strings.Trim(string(aux.OrderId), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("bitrue: invalid orderId %s: %w", aux.OrderId, err)
+	}
+	ack.OrderId = orderId
+	return nil
+}
+
+type Fill struct {
+	TradeId         int64        `json:"tradeId"`
+	Price           *decimal.Big `json:"price"`
+	Qty             *decimal.Big `json:"qty"`
+	Commission      *decimal.Big `json:"commission"`
+	CommissionAsset string       `json:"commissionAsset"`
+}
+
+// AvgPrice is the volume weighted price of the fills, or
+// cummulativeQuoteQty / executedQty without fills. nil if nothing executed.
+func (ack *OrderAck) AvgPrice() *decimal.Big {
+	ctx := decimal.Context128
+	quote, base := new(decimal.Big), new(decimal.Big)
+	if len(ack.Fills) > 0 {
+		for _, fill := range ack.Fills {
+			ctx.Add(quote, quote, ctx.Mul(new(decimal.Big), fill.Price, fill.Qty))
+			ctx.Add(base, base, fill.Qty)
+		}
+	} else if ack.CummulativeQuoteQty != nil && ack.ExecutedQty != nil {
+		quote.Copy(ack.CummulativeQuoteQty)
+		base.Copy(ack.ExecutedQty)
+	}
+	if base.Sign() == 0 {
+		return nil
+	}
+	return ctx.Quo(new(decimal.Big), quote, base)
+}
+
+// Commissions sums the fill commissions per asset.
+func (ack *OrderAck) Commissions() map[string]*decimal.Big {
+	commissions := make(map[string]*decimal.Big)
+	for _, fill := range ack.Fills {
+		total, ok := commissions[fill.CommissionAsset]
+		if !ok {
+			total = new(decimal.Big)
+			commissions[fill.CommissionAsset] = total
+		}
+		decimal.Context128.Add(total, total, fill.Commission)
+	}
+	return commissions
+}
+
+// NextClientOrderID returns a newClientOrderId from ClientOrderIDGenerator,
+// or "" without a generator. PlaceOrder calls it for orders without one, a
+// caller can also fill the request itself to know the ID beforehand.
+func (ex *Exchange) NextClientOrderID() string {
+	if ex.ClientOrderIDGenerator == nil {
+		return ""
+	}
+	return ex.ClientOrderIDGenerator.NextClientOrderID()
+}
+
+// OrderError is returned by PlaceOrder when sending an order with a
+// newClientOrderId fails. The order may still have reached the exchange,
+// look it up with QueryOrderByClientID.
+type OrderError struct {
+	Symbol        string
+	ClientOrderID string
+	Err           error
+}
+
+func (e *OrderError) Error() string {
+	return fmt.Sprintf("bitrue: order %s %s: %v", e.Symbol, e.ClientOrderID, e.Err)
+}
+
+func (e *OrderError) Unwrap() error {
+	return e.Err
+}
+
+// PlaceOrder checks the fields required by the order type, validates the
+// order against the symbol filters unless SkipValidation is set, and sends it.
+// A newClientOrderId from ClientOrderIDGenerator also makes the order safe
+// to retry.
+func (ex *Exchange) PlaceOrder(ctx context.Context, req OrderRequest) (*OrderAck, error) {
+	if err := req.check(); err != nil {
+		return nil, err
+	}
+	if req.NewClientOrderID == "" {
+		req.NewClientOrderID = ex.NextClientOrderID()
+	}
+	if err := ex.validate(req); err != nil {
+		return nil, err
+	}
+	body, err := ex.client.Signed(ctx, POST, "/api/v1/order", req.params())
+	if err != nil {
+		if req.NewClientOrderID != "" {
+			return nil, &OrderError{Symbol: req.Symbol, ClientOrderID: req.NewClientOrderID, Err: err}
+		}
+		return nil, err
+	}
+	ack := &OrderAck{}
+	err = json.Unmarshal([]byte(body), ack)
+	if err != nil {
+		return nil, err
+	}
+	return ack, nil
+}
+
+// FilterError names the symbol filter an order violates, Filter is a
+// filter type such as PRICE_FILTER, or STATUS when the symbol is not trading.
+type FilterError struct {
+	Symbol string
+	Filter string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("bitrue: %s order violates %s: %s", e.Symbol, e.Filter, e.Reason)
+}
+
+func (e *FilterError) Is(target error) bool {
+	return target == ErrFilterViolation
+}
+
+// ValidateOrder checks the order against the symbol status and the tick
+// size, step size, min/max quantity and min notional filters loaded from
+// exchangeInfo, without sending anything.
+func (ex *Exchange) ValidateOrder(req OrderRequest) error {
+	symbolInfo := ex.GetSymbolInfo(req.Symbol)
+	if symbolInfo == nil {
+		return ErrUnknownSymbol
+	}
+	symbol := symbolInfo.Symbol
+	if symbolInfo.Status != SymbolStatusTrading {
+		return &FilterError{Symbol: symbol, Filter: "STATUS", Reason: "symbol status is " + symbolInfo.Status}
+	}
+	if len(symbolInfo.OrderTypes) > 0 && !symbolInfo.SupportsOrderType(strings.ToUpper(req.Type)) {
+		return &FilterError{Symbol: symbol, Filter: "ORDER_TYPE", Reason: req.Type + " is not supported"}
+	}
+
+	isMarket := isMarketType(req.Type)
+	if priceFilter := symbolInfo.PriceFilter(); priceFilter != nil {
+		if err := checkPrice(symbol, "stopPrice", req.StopPrice, priceFilter); err != nil {
+			return err
+		}
+		if !isMarket {
+			if err := checkPrice(symbol, "price", req.Price, priceFilter); err != nil {
+				return err
+			}
+		}
+	}
+
+	if req.Quantity != nil {
+		filterType, lotSize := FilterLotSize, symbolInfo.LotSizeFilter()
+		if isMarket {
+			filterType, lotSize = FilterMarketLotSize, symbolInfo.MarketLotSizeFilter()
+		}
+		if lotSize != nil {
+			if !isZero(lotSize.MinQty) && req.Quantity.Cmp(lotSize.MinQty) < 0 {
+				return &FilterError{Symbol: symbol, Filter: filterType, Reason: fmt.Sprintf("quantity %f below minQty %f", req.Quantity, lotSize.MinQty)}
+			}
+			if !isZero(lotSize.MaxQty) && req.Quantity.Cmp(lotSize.MaxQty) > 0 {
+				return &FilterError{Symbol: symbol, Filter: filterType, Reason: fmt.Sprintf("quantity %f above maxQty %f", req.Quantity, lotSize.MaxQty)}
+			}
+			if !isMultiple(req.Quantity, lotSize.StepSize) {
+				return &FilterError{Symbol: symbol, Filter: filterType, Reason: fmt.Sprintf("quantity %f is not a multiple of stepSize %f", req.Quantity, lotSize.StepSize)}
+			}
+		}
+	}
+
+	if minNotional := symbolInfo.MinNotional(); minNotional != nil {
+		var notional *decimal.Big
+		switch {
+		case req.QuoteOrderQty != nil:
+			notional = req.QuoteOrderQty
+		case !isMarket && req.Price != nil && req.Quantity != nil:
+			notional = decimal.Context128.Mul(new(decimal.Big), req.Price, req.Quantity)
+		}
+		if notional != nil && notional.Cmp(minNotional) < 0 {
+			return &FilterError{Symbol: symbol, Filter: FilterMinNotional, Reason: fmt.Sprintf("notional %f below minNotional %f", notional, minNotional)}
+		}
+	}
+	return nil
+}
+
+func checkPrice(symbol, field string, price *decimal.Big, priceFilter *PriceFilter) error {
+	if price == nil {
+		return nil
+	}
+	if !isZero(priceFilter.MinPrice) && price.Cmp(priceFilter.MinPrice) < 0 {
+		return &FilterError{Symbol: symbol, Filter: FilterPrice, Reason: fmt.Sprintf("%s %f below minPrice %f", field, price, priceFilter.MinPrice)}
+	}
+	if !isZero(priceFilter.MaxPrice) && price.Cmp(priceFilter.MaxPrice) > 0 {
+		return &FilterError{Symbol: symbol, Filter: FilterPrice, Reason: fmt.Sprintf("%s %f above maxPrice %f", field, price, priceFilter.MaxPrice)}
+	}
+	if !isMultiple(price, priceFilter.TickSize) {
+		return &FilterError{Symbol: symbol, Filter: FilterPrice, Reason: fmt.Sprintf("%s %f is not a multiple of tickSize %f", field, price, priceFilter.TickSize)}
+	}
+	return nil
+}
+
+func (ex *Exchange) validate(req OrderRequest) error {
+	if ex.SkipValidation {
+		return nil
+	}
+	return ex.ValidateOrder(req)
+}
@@ -0,0 +1,131 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ericlagergren/decimal"
+)
+
+func dec(s string) *decimal.Big {
+	d, ok := new(decimal.Big).SetString(s)
+	if !ok {
+		panic("bad decimal " + s)
+	}
+	return d
+}
+
+func TestValidateOrder(t *testing.T) {
+	ex, server := newTestExchange(t, nil)
+	defer server.Close()
+
+	tests := []struct {
+		req    OrderRequest
+		filter string
+	}{
+		{OrderRequest{Symbol: "BTRUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: dec("0.1234"), Quantity: dec("100.5")}, ""},
+		{OrderRequest{Symbol: "BTRUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: dec("0.12345"), Quantity: dec("100")}, FilterPrice},
+		{OrderRequest{Symbol: "BTRUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: dec("0.1"), Quantity: dec("100.55")}, FilterLotSize},
+		{OrderRequest{Symbol: "BTRUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: dec("0.1"), Quantity: dec("0.5")}, FilterLotSize},
+		{OrderRequest{Symbol: "BTRUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: dec("0.1"), Quantity: dec("99")}, FilterMinNotional},
+		{OrderRequest{Symbol: "BTRUSDT", Side: SideSell, Type: OrderTypeMarket, Quantity: dec("5")}, ""},
+		{OrderRequest{Symbol: "ETHBTC", Side: SideBuy, Type: OrderTypeLimit, Price: dec("0.02"), Quantity: dec("1")}, "STATUS"},
+	}
+	for _, tt := range tests {
+		err := ex.ValidateOrder(tt.req)
+		if tt.filter == "" {
+			if err != nil {
+				t.Errorf("%+v: unexpected error %v", tt.req, err)
+			}
+			continue
+		}
+		var filterErr *FilterError
+		if !errors.As(err, &filterErr) || filterErr.Filter != tt.filter || !errors.Is(err, ErrFilterViolation) {
+			t.Errorf("%+v: expected %s violation, got %v", tt.req, tt.filter, err)
+		}
+	}
+
+	if err := ex.ValidateOrder(OrderRequest{Symbol: "NOPE"}); err != ErrUnknownSymbol {
+		t.Fatalf("expected ErrUnknownSymbol, got %v", err)
+	}
+}
+
+func TestBuyLimitValidates(t *testing.T) {
+	ex, server := newTestExchange(t, nil)
+	defer server.Close()
+
+	if _, err := ex.BuyLimit("BTRUSDT", 0.12345, 100); !errors.Is(err, ErrFilterViolation) {
+		t.Fatalf("expected filter violation before sending, got %v", err)
+	}
+}
+
+func TestPlaceOrder(t *testing.T) {
+	var sent url.Values
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent, _ = url.ParseQuery(string(body))
+		w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"9","clientOrderId":"abc","transactTime":1,"price":"0.1","origQty":"100","executedQty":"0","status":"NEW","timeInForce":"IOC","type":"LIMIT","side":"SELL"}`))
+	})
+	defer server.Close()
+
+	req := NewLimitOrder("BTRUSDT", SideSell, dec("0.1"), dec("100"))
+	req.TimeInForce = TimeInForceIOC
+	req.NewClientOrderID = "abc"
+	ack, err := ex.PlaceOrder(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.OrderId != 9 || ack.ClientOrderId != "abc" || ack.Status != "NEW" {
+		t.Fatalf("unexpected ack %+v", ack)
+	}
+	if sent.Get("timeInForce") != "IOC" || sent.Get("newClientOrderId") != "abc" || sent.Get("stopPrice") != "" {
+		t.Fatalf("unexpected params %v", sent)
+	}
+
+	_, err = ex.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTRUSDT", Side: SideSell, Type: OrderTypeStopLossLimit, Price: dec("0.1"), Quantity: dec("100")})
+	if !errors.Is(err, ErrInvalidOrderRequest) {
+		t.Fatalf("expected missing stopPrice error, got %v", err)
+	}
+}
+
+func TestOrderAckAvgPrice(t *testing.T) {
+	ack := &OrderAck{}
+	err := json.Unmarshal([]byte(`{"orderId":"1","executedQty":"3","cummulativeQuoteQty":"0.35","fills":[
+		{"price":"0.1","qty":"1","commission":"0.001","commissionAsset":"BTR"},
+		{"price":"0.125","qty":"2","commission":"0.002","commissionAsset":"BTR"}]}`), ack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ack.AvgPrice(); got.Cmp(decimal.Context128.Quo(new(decimal.Big), dec("0.35"), dec("3"))) != 0 {
+		t.Fatalf("unexpected avg price %s", got)
+	}
+	if got := ack.Commissions()["BTR"]; got.Cmp(dec("0.003")) != 0 {
+		t.Fatalf("unexpected commission %s", got)
+	}
+	if (&OrderAck{}).AvgPrice() != nil {
+		t.Fatal("avg price of an unfilled order should be nil")
+	}
+}
+
+func TestOrderAckOrderId(t *testing.T) {
+	for _, payload := range []string{
+		`{"symbol":"BTRUSDT","orderId":"123456789012","status":"NEW"}`,
+		`{"symbol":"BTRUSDT","orderId":123456789012,"status":"NEW"}`,
+	} {
+		ack := &OrderAck{}
+		if err := json.Unmarshal([]byte(payload), ack); err != nil {
+			t.Fatalf("%s: %v", payload, err)
+		}
+		if ack.OrderId != 123456789012 || ack.Symbol != "BTRUSDT" || ack.Status != "NEW" {
+			t.Fatalf("%s: unexpected ack %+v", payload, ack)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"orderId":"x"}`), &OrderAck{}); err == nil {
+		t.Fatal("expected error for a malformed orderId")
+	}
+}
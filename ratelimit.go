@@ -0,0 +1,242 @@
+package bitrue
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RateLimitRequestWeight = "REQUEST_WEIGHT"
+	RateLimitOrders        = "ORDERS"
+)
+
+// defaultRetryAfter is used when a 429/418 response has no Retry-After header.
+const defaultRetryAfter = time.Second
+
+// RateLimit is an entry of the rateLimits section of /api/v1/exchangeInfo.
+type RateLimit struct {
+	RateLimitType string `json:"rateLimitType"`
+	Interval      string `json:"interval"`
+	IntervalNum   int    `json:"intervalNum"`
+	Limit         int    `json:"limit"`
+}
+
+func (rl RateLimit) duration() time.Duration {
+	num := rl.IntervalNum
+	if num <= 0 {
+		num = 1
+	}
+	switch strings.ToUpper(rl.Interval) {
+	case "SECOND":
+		return time.Duration(num) * time.Second
+	case "MINUTE":
+		return time.Duration(num) * time.Minute
+	case "HOUR":
+		return time.Duration(num) * time.Hour
+	case "DAY":
+		return time.Duration(num) * 24 * time.Hour
+	}
+	return 0
+}
+
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	// tokens per second
+	rate float64
+	last time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// wait returns how long to wait until cost tokens are available.
+func (b *tokenBucket) wait(cost float64) time.Duration {
+	if cost > b.capacity {
+		cost = b.capacity
+	}
+	if b.tokens >= cost {
+		return 0
+	}
+	return time.Duration((cost - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) take(cost float64) {
+	if cost > b.capacity {
+		cost = b.capacity
+	}
+	b.tokens -= cost
+}
+
+// RateLimiter is a weighted token bucket limiter for request weight and
+// order count limits. One limiter may be shared by several clients which use
+// the same ip or account.
+type RateLimiter struct {
+	mu           sync.Mutex
+	weights      []*tokenBucket
+	orders       []*tokenBucket
+	blockedUntil time.Time
+}
+
+func NewRateLimiter(limits []RateLimit) *RateLimiter {
+	l := &RateLimiter{}
+	l.SetLimits(limits)
+	return l
+}
+
+// SetLimits replaces the buckets, normally with the rateLimits of exchangeInfo.
+func (l *RateLimiter) SetLimits(limits []RateLimit) {
+	now := time.Now()
+	var weights, orders []*tokenBucket
+	for _, limit := range limits {
+		d := limit.duration()
+		if d <= 0 || limit.Limit <= 0 {
+			continue
+		}
+		bucket := &tokenBucket{
+			capacity: float64(limit.Limit),
+			tokens:   float64(limit.Limit),
+			rate:     float64(limit.Limit) / d.Seconds(),
+			last:     now,
+		}
+		switch strings.ToUpper(limit.RateLimitType) {
+		case RateLimitRequestWeight:
+			weights = append(weights, bucket)
+		case RateLimitOrders:
+			orders = append(orders, bucket)
+		}
+	}
+
+	l.mu.Lock()
+	l.weights = weights
+	l.orders = orders
+	l.mu.Unlock()
+}
+
+// Wait takes weight request tokens, plus one order token when order is true,
+// blocking until they are available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, weight int, order bool) error {
+	for {
+		delay := l.reserve(weight, order)
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Allow is the non blocking Wait, it reports whether the tokens were taken.
+func (l *RateLimiter) Allow(weight int, order bool) bool {
+	return l.reserve(weight, order) <= 0
+}
+
+// reserve takes the tokens if they are all available, otherwise it takes
+// nothing and returns how long to wait.
+func (l *RateLimiter) reserve(weight int, order bool) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	delay := l.blockedUntil.Sub(now)
+	for _, b := range l.weights {
+		b.refill(now)
+		if d := b.wait(float64(weight)); d > delay {
+			delay = d
+		}
+	}
+	if order {
+		for _, b := range l.orders {
+			b.refill(now)
+			if d := b.wait(1); d > delay {
+				delay = d
+			}
+		}
+	}
+	if delay > 0 {
+		return delay
+	}
+	for _, b := range l.weights {
+		b.take(float64(weight))
+	}
+	if order {
+		for _, b := range l.orders {
+			b.take(1)
+		}
+	}
+	return 0
+}
+
+// Backoff blocks every request of the limiter for d, used after 429/418.
+func (l *RateLimiter) Backoff(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// retryAfter parses the Retry-After header, in seconds or as http date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return defaultRetryAfter
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return defaultRetryAfter
+}
+
+// endpointWeight returns the request weight of an endpoint and whether it
+// counts against the order limits.
+func endpointWeight(method, path string, params map[string]string) (int, bool) {
+	_, hasSymbol := params["symbol"]
+	switch path {
+	case "/api/v1/order":
+		return 1, method == POST
+	case "/api/v1/openOrders":
+		if hasSymbol {
+			return 1, false
+		}
+		return 40, false
+	case "/api/v1/ticker/24hr":
+		if hasSymbol {
+			return 1, false
+		}
+		return 40, false
+	case "/api/v1/ticker/price", "/api/v1/ticker/bookTicker":
+		if hasSymbol {
+			return 1, false
+		}
+		return 2, false
+	case "/api/v1/depth":
+		switch limit, _ := strconv.Atoi(params["limit"]); {
+		case limit > 500:
+			return 10, false
+		case limit > 100:
+			return 5, false
+		}
+		return 1, false
+	case "/api/v1/account", "/api/v1/allOrders", "/api/v1/myTrades", "/api/v1/historicalTrades":
+		return 5, false
+	}
+	return 1, false
+}
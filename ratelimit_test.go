@@ -0,0 +1,56 @@
+package bitrue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter([]RateLimit{
+		{RateLimitType: RateLimitRequestWeight, Interval: "MINUTE", IntervalNum: 1, Limit: 10},
+		{RateLimitType: RateLimitOrders, Interval: "SECOND", IntervalNum: 1, Limit: 2},
+	})
+	if !limiter.Allow(1, true) || !limiter.Allow(1, true) {
+		t.Fatal("first orders should pass")
+	}
+	if limiter.Allow(1, true) {
+		t.Fatal("third order in the same second should be limited")
+	}
+	if !limiter.Allow(5, false) {
+		t.Fatal("weight is still available")
+	}
+	if limiter.Allow(5, false) {
+		t.Fatal("weight should be exhausted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, 5, false); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline, got %v", err)
+	}
+}
+
+func TestClientBacksOffOn429(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient("ak", "sk", WithBaseURL(server.URL), WithRateLimitFailFast())
+	if _, err := client.Get(context.Background(), "/api/v1/time", nil); !errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("expected ErrTooManyRequests, got %v", err)
+	}
+	if _, err := client.Get(context.Background(), "/api/v1/time", nil); err != ErrRateLimited {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("request after 429 should not reach the server, got %d calls", calls)
+	}
+}
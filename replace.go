@@ -0,0 +1,67 @@
+package bitrue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// ErrOrderStillOpen is returned by ReplaceOrder when the old order is not
+// final after the cancel, nothing new is submitted then.
+var ErrOrderStillOpen = errors.New("bitrue: order still open after cancel")
+
+// ReplaceResult holds the final state of the replaced order and the ack of
+// the new one. NewOrder is nil when the old order had already executed the
+// whole new quantity.
+type ReplaceResult struct {
+	OldOrder *OrderData
+	NewOrder *OrderAck
+}
+
+// ReplaceOrder cancels orderId, reads back how much of it executed and
+// places newReq with its quantity reduced by that amount, so a fill racing
+// the cancel never doubles the exposure. A nil newReq.Quantity reuses the
+// quantity of the old order.
+func (ex *Exchange) ReplaceOrder(symbol string, orderId int64, newReq OrderRequest) (*ReplaceResult, error) {
+	return ex.ReplaceOrderCtx(context.Background(), symbol, orderId, newReq)
+}
+
+func (ex *Exchange) ReplaceOrderCtx(ctx context.Context, symbol string, orderId int64, newReq OrderRequest) (*ReplaceResult, error) {
+	if _, err := ex.CancelCtx(ctx, symbol, orderId); err != nil && !errors.Is(err, ErrUnknownOrder) {
+		return nil, err
+	}
+	oldOrder, err := ex.QueryOrderCtx(ctx, symbol, orderId)
+	if err != nil {
+		return nil, err
+	}
+	result := &ReplaceResult{OldOrder: oldOrder}
+	if !oldOrder.IsFinal() {
+		return result, fmt.Errorf("%w: order %d is %s", ErrOrderStillOpen, orderId, oldOrder.Status)
+	}
+
+	executed, ok := new(decimal.Big).SetString(oldOrder.ExecutedQty)
+	if !ok {
+		executed = new(decimal.Big)
+	}
+	quantity := newReq.Quantity
+	if quantity == nil {
+		quantity = &oldOrder.OrigQty
+	}
+	remaining := decimal.Context128.Sub(new(decimal.Big), quantity, executed)
+	if remaining.Sign() <= 0 {
+		return result, nil
+	}
+
+	if newReq.Symbol == "" {
+		newReq.Symbol = symbol
+	}
+	newReq.Quantity = remaining
+	ack, err := ex.PlaceOrder(ctx, newReq)
+	if err != nil {
+		return result, err
+	}
+	result.NewOrder = ack
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package bitrue
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestReplaceOrder(t *testing.T) {
+	status, executed := "CANCELED", "30"
+	var placed url.Values
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		params, _ := url.ParseQuery(string(body))
+		switch r.Method {
+		case DELETE:
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"1"}`))
+		case GET:
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"1","status":"` + status + `","origQty":"100","executedQty":"` + executed + `"}`))
+		case POST:
+			placed = params
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"2"}`))
+		}
+	})
+	defer server.Close()
+
+	result, err := ex.ReplaceOrder("BTRUSDT", 1, NewLimitOrder("", SideBuy, dec("0.2"), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.OldOrder.Filled() != 30 || result.NewOrder.OrderId != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if placed.Get("quantity") != "70" || placed.Get("price") != "0.2" {
+		t.Fatalf("new order should be reduced by the executed amount: %v", placed)
+	}
+
+	placed = nil
+	status, executed = "FILLED", "100"
+	result, err = ex.ReplaceOrder("BTRUSDT", 1, NewLimitOrder("", SideBuy, dec("0.2"), dec("100")))
+	if err != nil || result.NewOrder != nil || placed != nil {
+		t.Fatalf("fully executed order must not be replaced: %+v %v", result, err)
+	}
+
+	status = "NEW"
+	_, err = ex.ReplaceOrderCtx(context.Background(), "BTRUSDT", 1, NewLimitOrder("", SideBuy, dec("0.2"), dec("100")))
+	if !errors.Is(err, ErrOrderStillOpen) || placed != nil {
+		t.Fatalf("expected ErrOrderStillOpen, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package bitrue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried. Public and
+// signed GET requests are retried freely; a new order is only retried when
+// it carries a newClientOrderId, and only after checking that the first
+// attempt did not reach the matching engine.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt, values <= 1 disable retries.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy, RetryPolicy{} disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// backoff is exponential with full jitter.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+func (p RetryPolicy) retry(ctx context.Context, fn func() (string, error)) (string, error) {
+	return p.retryWithCheck(ctx, fn, nil)
+}
+
+// retryWithCheck calls check before every retry, a non empty body from
+// check ends the loop as the successful result.
+func (p RetryPolicy) retryWithCheck(ctx context.Context, fn func() (string, error), check func() (string, bool, error)) (string, error) {
+	var prevErr error
+	for attempt := 0; ; attempt++ {
+		body, err := fn()
+		if err == ErrRateLimited && prevErr != nil {
+			// a fail fast limiter refused the retry, e.g. after a 429,
+			// the server's answer is the more useful error
+			return body, prevErr
+		}
+		prevErr = err
+		if err == nil || attempt+1 >= p.MaxAttempts || !isTransient(ctx, err) {
+			return body, err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return body, err
+		case <-timer.C:
+		}
+
+		if check != nil {
+			landed, ok, checkErr := check()
+			if checkErr != nil {
+				return body, err
+			}
+			if ok {
+				return landed, nil
+			}
+		}
+	}
+}
+
+// isTransient reports whether err may succeed on a retry: transport
+// errors, 5xx and 429 responses. Errors such as a malformed body or an
+// invalid request fail the same way every time and are not retried.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || err == ErrRateLimited {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus >= http.StatusInternalServerError || apiErr.HTTPStatus == http.StatusTooManyRequests
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	// *url.Error implements net.Error itself, look at what it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (c *Client) getWithRetry(ctx context.Context, strUrl string, params map[string]string, ak string) (string, error) {
+	return c.RetryPolicy.retry(ctx, func() (string, error) {
+		return c.get(ctx, strUrl, params, ak)
+	})
+}
+
+func (c *Client) signedWithRetry(ctx context.Context, method string, url string, params map[string]string, ak, sk string) (string, error) {
+	send := func() (string, error) {
+		return c.signed(ctx, method, url, params, ak, sk)
+	}
+	switch {
+	case method == GET:
+		return c.RetryPolicy.retry(ctx, send)
+	case method == POST && strings.HasSuffix(url, "/api/v1/order") && params["newClientOrderId"] != "":
+		return c.RetryPolicy.retryWithCheck(ctx, send, func() (string, bool, error) {
+			return c.findOrderByClientID(ctx, url, params["symbol"], params["newClientOrderId"], ak, sk)
+		})
+	}
+	return send()
+}
+
+// findOrderByClientID looks up an order which may have landed during a
+// failed attempt.
+func (c *Client) findOrderByClientID(ctx context.Context, url, symbol, clientOrderId, ak, sk string) (string, bool, error) {
+	params := map[string]string{
+		"symbol":            symbol,
+		"origClientOrderId": clientOrderId,
+	}
+	body, err := c.signed(ctx, GET, url, params, ak, sk)
+	if errors.Is(err, ErrUnknownOrder) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return body, true, nil
+}
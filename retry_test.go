@@ -0,0 +1,111 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+func TestRetryGet(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"serverTime":1}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("ak", "sk", WithBaseURL(server.URL), WithRetryPolicy(fastRetry))
+	if _, err := client.Get(context.Background(), "/api/v1/time", nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryOrder(t *testing.T) {
+	posts, queries := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		values, _ := url.ParseQuery(string(body))
+		switch r.Method {
+		case POST:
+			posts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case GET:
+			queries++
+			if values.Get("origClientOrderId") != "grid-1" {
+				t.Errorf("unexpected query %v", values)
+			}
+			w.Write([]byte(`{"symbol":"BTRUSDT","orderId":"7","clientOrderId":"grid-1"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("ak", "sk", WithBaseURL(server.URL), WithRetryPolicy(fastRetry))
+	params := map[string]string{"symbol": "BTRUSDT", "side": "BUY", "type": "LIMIT"}
+	if _, err := client.Signed(context.Background(), POST, "/api/v1/order", params); err == nil {
+		t.Fatal("expected error")
+	}
+	if posts != 1 || queries != 0 {
+		t.Fatalf("order without client id must not be retried, posts=%d queries=%d", posts, queries)
+	}
+
+	posts = 0
+	params["newClientOrderId"] = "grid-1"
+	body, err := client.Signed(context.Background(), POST, "/api/v1/order", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts != 1 || queries != 1 {
+		t.Fatalf("landed order must not be resubmitted, posts=%d queries=%d", posts, queries)
+	}
+	if body == "" {
+		t.Fatal("expected the queried order as result")
+	}
+}
+
+func TestRetryOnlyTransientErrors(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{syntaxErr, false},
+		{&APIError{HTTPStatus: http.StatusBadRequest}, false},
+		{&APIError{HTTPStatus: http.StatusBadGateway}, true},
+		{&APIError{HTTPStatus: http.StatusTooManyRequests}, true},
+		{ErrRateLimited, false},
+		{&url.Error{Op: "Get", URL: "x", Err: errors.New(`unsupported protocol scheme ""`)}, false},
+		{&url.Error{Op: "Get", URL: "x", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{io.ErrUnexpectedEOF, true},
+	}
+	for _, test := range tests {
+		if got := isTransient(context.Background(), test.err); got != test.want {
+			t.Errorf("isTransient(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+
+	calls := 0
+	_, err := fastRetry.retry(context.Background(), func() (string, error) {
+		calls++
+		return "", syntaxErr
+	})
+	if err != syntaxErr || calls != 1 {
+		t.Fatalf("malformed body must not be retried, calls=%d err=%v", calls, err)
+	}
+}
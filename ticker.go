@@ -0,0 +1,126 @@
+package bitrue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// Ticker24hr is the rolling 24 hour statistics of a symbol.
+type Ticker24hr struct {
+	Symbol             string       `json:"symbol"`
+	PriceChange        *decimal.Big `json:"priceChange"`
+	PriceChangePercent *decimal.Big `json:"priceChangePercent"`
+	WeightedAvgPrice   *decimal.Big `json:"weightedAvgPrice"`
+	PrevClosePrice     *decimal.Big `json:"prevClosePrice"`
+	LastPrice          *decimal.Big `json:"lastPrice"`
+	LastQty            *decimal.Big `json:"lastQty"`
+	BidPrice           *decimal.Big `json:"bidPrice"`
+	AskPrice           *decimal.Big `json:"askPrice"`
+	OpenPrice          *decimal.Big `json:"openPrice"`
+	HighPrice          *decimal.Big `json:"highPrice"`
+	LowPrice           *decimal.Big `json:"lowPrice"`
+	Volume             *decimal.Big `json:"volume"`
+	QuoteVolume        *decimal.Big `json:"quoteVolume"`
+	OpenTime           int64        `json:"openTime"`
+	CloseTime          int64        `json:"closeTime"`
+	FirstId            int64        `json:"firstId"`
+	LastId             int64        `json:"lastId"`
+	Count              int64        `json:"count"`
+}
+
+// 24小时内的价格变化
+func (ex *Exchange) Get24hrTicker(symbol string) (*Ticker24hr, error) {
+	return ex.Get24hrTickerCtx(context.Background(), symbol)
+}
+
+func (ex *Exchange) Get24hrTickerCtx(ctx context.Context, symbol string) (*Ticker24hr, error) {
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	body, err := ex.client.Get(ctx, "/api/v1/ticker/24hr", params)
+	if err != nil {
+		return nil, err
+	}
+	// bitrue answers with an array even for a single symbol
+	tickers := make([]*Ticker24hr, 0)
+	err = unmarshalOneOrMany([]byte(body), &tickers)
+	if err != nil {
+		return nil, err
+	}
+	for _, ticker := range tickers {
+		if strings.EqualFold(ticker.Symbol, symbol) {
+			return ticker, nil
+		}
+	}
+	if len(tickers) == 1 {
+		return tickers[0], nil
+	}
+	return nil, ErrUnknownSymbol
+}
+
+// GetAll24hrTickers returns the statistics of every symbol in one request.
+func (ex *Exchange) GetAll24hrTickers() ([]*Ticker24hr, error) {
+	return ex.GetAll24hrTickersCtx(context.Background())
+}
+
+func (ex *Exchange) GetAll24hrTickersCtx(ctx context.Context) ([]*Ticker24hr, error) {
+	body, err := ex.client.Get(ctx, "/api/v1/ticker/24hr", nil)
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]*Ticker24hr, 0)
+	err = unmarshalOneOrMany([]byte(body), &tickers)
+	if err != nil {
+		return nil, err
+	}
+	return tickers, nil
+}
+
+// GetAllTickerPrices returns the last price of every symbol in one request.
+func (ex *Exchange) GetAllTickerPrices() ([]*PriceTicker, error) {
+	return ex.GetAllTickerPricesCtx(context.Background())
+}
+
+func (ex *Exchange) GetAllTickerPricesCtx(ctx context.Context) ([]*PriceTicker, error) {
+	body, err := ex.client.Get(ctx, "/api/v1/ticker/price", nil)
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]*PriceTicker, 0)
+	err = unmarshalOneOrMany([]byte(body), &tickers)
+	if err != nil {
+		return nil, err
+	}
+	return tickers, nil
+}
+
+// GetAllBookTickers returns the best bid/ask of every symbol in one request.
+func (ex *Exchange) GetAllBookTickers() ([]*BookTicker, error) {
+	return ex.GetAllBookTickersCtx(context.Background())
+}
+
+func (ex *Exchange) GetAllBookTickersCtx(ctx context.Context) ([]*BookTicker, error) {
+	body, err := ex.client.Get(ctx, "/api/v1/ticker/bookTicker", nil)
+	if err != nil {
+		return nil, err
+	}
+	tickers := make([]*BookTicker, 0)
+	err = unmarshalOneOrMany([]byte(body), &tickers)
+	if err != nil {
+		return nil, err
+	}
+	return tickers, nil
+}
+
+// unmarshalOneOrMany decodes an array, or a single object as one element
+// array, into the slice pointed to by v.
+func unmarshalOneOrMany(data []byte, v interface{}) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,46 @@
+package bitrue
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTickers(t *testing.T) {
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		hasSymbol := r.URL.Query().Get("symbol") != ""
+		switch r.URL.Path {
+		case "/api/v1/ticker/24hr":
+			if hasSymbol {
+				w.Write([]byte(`[{"symbol":"BTRUSDT","priceChangePercent":"-1.5","openPrice":"0.1","highPrice":"0.12","lowPrice":"0.09","lastPrice":"0.11","volume":"1000","quoteVolume":"110","count":42}]`))
+				return
+			}
+			w.Write([]byte(`[{"symbol":"BTRUSDT","lastPrice":"0.11"},{"symbol":"ETHBTC","lastPrice":"0.02"}]`))
+		case "/api/v1/ticker/price":
+			w.Write([]byte(`[{"symbol":"BTRUSDT","price":"0.11"},{"symbol":"ETHBTC","price":"0.02"}]`))
+		case "/api/v1/ticker/bookTicker":
+			w.Write([]byte(`[{"symbol":"BTRUSDT","bidPrice":"0.1","bidQty":"5","askPrice":"0.12","askQty":"7"}]`))
+		}
+	})
+	defer server.Close()
+
+	ticker, err := ex.Get24hrTicker("btrusdt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.Count != 42 || ticker.PriceChangePercent.Cmp(dec("-1.5")) != 0 || ticker.QuoteVolume.Cmp(dec("110")) != 0 {
+		t.Fatalf("unexpected ticker %+v", ticker)
+	}
+
+	tickers, err := ex.GetAll24hrTickers()
+	if err != nil || len(tickers) != 2 {
+		t.Fatalf("unexpected tickers %v %v", tickers, err)
+	}
+	prices, err := ex.GetAllTickerPrices()
+	if err != nil || len(prices) != 2 || prices[1].Price.Cmp(dec("0.02")) != 0 {
+		t.Fatalf("unexpected prices %v %v", prices, err)
+	}
+	books, err := ex.GetAllBookTickers()
+	if err != nil || len(books) != 1 || books[0].GetSellPrice() != 0.12 {
+		t.Fatalf("unexpected book tickers %v %v", books, err)
+	}
+}
@@ -0,0 +1,129 @@
+package bitrue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+const defaultTimeSamples = 5
+
+// ServerTime returns bitrue server time, GET /api/v1/time
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	body, err := c.Get(ctx, "/api/v1/time", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	serverTime := gjson.Get(body, "serverTime")
+	if !serverTime.Exists() {
+		return time.Time{}, errors.New("bitrue: serverTime missing in response: " + body)
+	}
+	return msToTime(serverTime.Int()), nil
+}
+
+// SyncTime measures the clock offset against the server with the given
+// number of samples. Every sample is corrected by half of its round trip and
+// the median is kept, so a single slow response does not skew the result.
+func (c *Client) SyncTime(ctx context.Context, samples int) (time.Duration, error) {
+	if samples <= 0 {
+		samples = defaultTimeSamples
+	}
+	offsets := make([]time.Duration, 0, samples)
+	var lastErr error
+	for i := 0; i < samples; i++ {
+		offset, err := c.timeSample(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		offsets = append(offsets, offset)
+	}
+	if len(offsets) == 0 {
+		return 0, lastErr
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	offset := offsets[len(offsets)/2]
+
+	c.timeMu.Lock()
+	c.timeOffset = offset
+	c.timeMu.Unlock()
+	return offset, nil
+}
+
+// timeSample is one offset measurement. It is never retried and the round
+// trip is taken from the request being written to the first response byte,
+// so rate limiter waits and backoff sleeps do not bias it.
+func (c *Client) timeSample(ctx context.Context) (time.Duration, error) {
+	request, err := http.NewRequest(GET, c.BaseURL+"/api/v1/time", nil)
+	if err != nil {
+		return 0, err
+	}
+	var sent, received time.Time
+	trace := &httptrace.ClientTrace{
+		WroteRequest:         func(httptrace.WroteRequestInfo) { sent = time.Now() },
+		GotFirstResponseByte: func() { received = time.Now() },
+	}
+	weight, order := endpointWeight(GET, request.URL.Path, nil)
+	body, err := c.do(httptrace.WithClientTrace(ctx, trace), request, weight, order)
+	if err != nil {
+		return 0, err
+	}
+	serverTime := gjson.Get(body, "serverTime")
+	if !serverTime.Exists() || sent.IsZero() || received.IsZero() {
+		return 0, errors.New("bitrue: serverTime missing in response: " + body)
+	}
+	rtt := received.Sub(sent)
+	return msToTime(serverTime.Int()).Sub(sent.Add(rtt / 2)), nil
+}
+
+// StartTimeSync keeps the clock offset fresh in the background until ctx is
+// done. The first sync runs before it returns.
+func (c *Client) StartTimeSync(ctx context.Context, interval time.Duration) error {
+	if _, err := c.SyncTime(ctx, defaultTimeSamples); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.SyncTime(ctx, defaultTimeSamples); err != nil && ctx.Err() == nil {
+					log.Println("bitrue time sync error:", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// TimeOffset is server time minus local time as last measured by SyncTime.
+func (c *Client) TimeOffset() time.Duration {
+	c.timeMu.RLock()
+	defer c.timeMu.RUnlock()
+	return c.timeOffset
+}
+
+// Now is the local clock corrected by TimeOffset.
+func (c *Client) Now() time.Time {
+	return time.Now().Add(c.TimeOffset())
+}
+
+func msToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+func timeToMs(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
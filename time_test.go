@@ -0,0 +1,92 @@
+package bitrue
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSyncTimeAndRecvWindow(t *testing.T) {
+	const skew = 5 * time.Second
+	var signedBody url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/time":
+			fmt.Fprintf(w, `{"serverTime":%d}`, timeToMs(time.Now().Add(skew)))
+		case "/api/v1/account":
+			body, _ := ioutil.ReadAll(r.Body)
+			signedBody, _ = url.ParseQuery(string(body))
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("ak", "sk", WithBaseURL(server.URL), WithRecvWindow(3*time.Second))
+	offset, err := client.SyncTime(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset < skew-time.Second || offset > skew+time.Second {
+		t.Fatalf("offset %v is not close to %v", offset, skew)
+	}
+
+	if _, err := client.Signed(context.Background(), GET, "/api/v1/account", nil); err != nil {
+		t.Fatal(err)
+	}
+	if signedBody.Get("recvWindow") != "3000" {
+		t.Fatalf("unexpected recvWindow %q", signedBody.Get("recvWindow"))
+	}
+	ts, _ := strconv.ParseInt(signedBody.Get("timestamp"), 10, 64)
+	if d := msToTime(ts).Sub(time.Now().Add(skew)); d < -time.Second || d > time.Second {
+		t.Fatalf("timestamp is not corrected by the offset: %v", d)
+	}
+}
+
+func TestPackageServerTimeUsesDefaultClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"serverTime":1600000000000}`))
+	}))
+	defer server.Close()
+	oldHost, oldBaseURL := https, defaultClient.BaseURL
+	defer func() { https, defaultClient.BaseURL = oldHost, oldBaseURL }()
+
+	SetHost(server.URL)
+	serverTime, err := GetServerTime()
+	if err != nil || timeToMs(serverTime) != 1600000000000 {
+		t.Fatalf("unexpected server time %v %v", serverTime, err)
+	}
+}
+
+func TestSyncTimeIgnoresLimiterAndRetries(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintf(w, `{"serverTime":%d}`, timeToMs(time.Now()))
+	}))
+	defer server.Close()
+
+	client := NewClient("ak", "sk", WithBaseURL(server.URL), WithRetryPolicy(fastRetry))
+	client.SetRateLimits([]RateLimit{{RateLimitType: "REQUEST_WEIGHT", Interval: "SECOND", IntervalNum: 1, Limit: 1}})
+	// the failed first sample is not retried, the other two wait for the
+	// limiter without that wait counting as round trip
+	offset, err := client.SyncTime(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 requests, got %d", calls)
+	}
+	if offset < -100*time.Millisecond || offset > 100*time.Millisecond {
+		t.Fatalf("offset %v is biased by the limiter wait", offset)
+	}
+}
@@ -0,0 +1,65 @@
+package bitrue
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ericlagergren/decimal"
+)
+
+// TradeTick is a public trade of the trade ticker channel, Side is the
+// taker side, SideBuy or SideSell, and Time is in milliseconds.
+type TradeTick struct {
+	ID    int64
+	Price *decimal.Big
+	Qty   *decimal.Big
+	Side  string
+	Time  int64
+}
+
+type tradeTickerFrame struct {
+	Channel string
+	Tick    struct {
+		Data []struct {
+			Id     int64
+			Price  *decimal.Big
+			Amount *decimal.Big
+			Side   string
+			Ts     int64
+		}
+	}
+}
+
+// TradeStream delivers the trades of one symbol in the order received.
+type TradeStream struct {
+	Symbol string
+	C      <-chan *TradeTick
+
+	wsStream
+}
+
+// SubscribeTrades streams the public trades of symbol.
+func (c *WsClient) SubscribeTrades(symbol string) (*TradeStream, error) {
+	channel := "market_" + strings.ToLower(symbol) + "_trade_ticker"
+	out := make(chan *TradeTick, 1)
+	stream, err := c.subscribeStream(channel, func(msg []byte) error {
+		frame := &tradeTickerFrame{}
+		if err := json.Unmarshal(msg, frame); err != nil {
+			return err
+		}
+		for _, trade := range frame.Tick.Data {
+			out <- &TradeTick{
+				ID:    trade.Id,
+				Price: trade.Price,
+				Qty:   trade.Amount,
+				Side:  strings.ToUpper(trade.Side),
+				Time:  trade.Ts,
+			}
+		}
+		return nil
+	}, func() { close(out) })
+	if err != nil {
+		return nil, err
+	}
+	return &TradeStream{Symbol: symbol, C: out, wsStream: stream}, nil
+}
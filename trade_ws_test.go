@@ -0,0 +1,62 @@
+package bitrue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+func TestSubscribeTrades(t *testing.T) {
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		channel := gjson.GetBytes(msg, "params.channel").String()
+		if channel != "market_btrusdt_trade_ticker" {
+			t.Errorf("unexpected channel %s", channel)
+		}
+		conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"channel":"`+channel+`","ts":1,"tick":{"id":1,"ts":1,"data":[`+
+			`{"id":11,"side":"buy","price":0.30000000,"vol":0.6,"amount":"2","ts":1600000000000},`+
+			`{"id":12,"side":"SELL","price":"0.29","vol":0.29,"amount":1,"ts":1600000000001}]}}`))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(fastWsReconnect))
+	defer client.Close()
+	stream, err := client.SubscribeTrades("BTRUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ticks []*TradeTick
+	for len(ticks) < 2 {
+		select {
+		case tick := <-stream.C:
+			ticks = append(ticks, tick)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for trades")
+		}
+	}
+	if ticks[0].ID != 11 || ticks[0].Side != SideBuy || ticks[0].Price.Cmp(dec("0.3")) != 0 || ticks[0].Qty.Cmp(dec("2")) != 0 || ticks[0].Time != 1600000000000 {
+		t.Fatalf("unexpected tick %+v", ticks[0])
+	}
+	if ticks[1].ID != 12 || ticks[1].Side != SideSell || ticks[1].Price.Cmp(dec("0.29")) != 0 {
+		t.Fatalf("unexpected tick %+v", ticks[1])
+	}
+
+	if err := stream.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-stream.C; ok {
+		t.Fatal("expected closed stream")
+	}
+}
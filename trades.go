@@ -0,0 +1,102 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// 最近成交
+func (ex *Exchange) GetTrades(symbol string, limit int64) ([]Trade, error) {
+	return ex.GetTradesCtx(context.Background(), symbol, limit)
+}
+
+func (ex *Exchange) GetTradesCtx(ctx context.Context, symbol string, limit int64) ([]Trade, error) {
+	return getTrades(ctx, ex.client, symbol, limit)
+}
+
+func getTrades(ctx context.Context, client *Client, symbol string, limit int64) ([]Trade, error) {
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	params["limit"] = cast.ToString(limit)
+	body, err := client.Get(ctx, "/api/v1/trades", params)
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]Trade, 0)
+	err = json.Unmarshal([]byte(body), &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// GetHistoricalTrades returns older trades starting at fromId, it needs the
+// api key but no signature. fromId <= 0 returns the most recent trades.
+func (ex *Exchange) GetHistoricalTrades(symbol string, fromId int64, limit int) ([]Trade, error) {
+	return ex.GetHistoricalTradesCtx(context.Background(), symbol, fromId, limit)
+}
+
+func (ex *Exchange) GetHistoricalTradesCtx(ctx context.Context, symbol string, fromId int64, limit int) ([]Trade, error) {
+	params := make(map[string]string)
+	params["symbol"] = symbol
+	if fromId > 0 {
+		params["fromId"] = strconv.FormatInt(fromId, 10)
+	}
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+	body, err := ex.client.KeyedGet(ctx, "/api/v1/historicalTrades", params)
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]Trade, 0)
+	err = json.Unmarshal([]byte(body), &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// AggTradesRequest filters GET /api/v1/aggTrades, zero fields are not sent.
+type AggTradesRequest struct {
+	Symbol    string
+	FromId    int64
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+}
+
+func (ex *Exchange) GetAggTrades(req AggTradesRequest) ([]AggTrade, error) {
+	return ex.GetAggTradesCtx(context.Background(), req)
+}
+
+func (ex *Exchange) GetAggTradesCtx(ctx context.Context, req AggTradesRequest) ([]AggTrade, error) {
+	params := make(map[string]string)
+	params["symbol"] = req.Symbol
+	if req.FromId > 0 {
+		params["fromId"] = strconv.FormatInt(req.FromId, 10)
+	}
+	if !req.StartTime.IsZero() {
+		params["startTime"] = strconv.FormatInt(timeToMs(req.StartTime), 10)
+	}
+	if !req.EndTime.IsZero() {
+		params["endTime"] = strconv.FormatInt(timeToMs(req.EndTime), 10)
+	}
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+	body, err := ex.client.Get(ctx, "/api/v1/aggTrades", params)
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]AggTrade, 0)
+	err = json.Unmarshal([]byte(body), &trades)
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
@@ -0,0 +1,61 @@
+package bitrue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrades(t *testing.T) {
+	ex, server := newTestExchange(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v1/trades":
+			w.Write([]byte(`[{"id":7,"price":"0.1","qty":"3.5","time":1600000000000,"isBuyerMaker":true}]`))
+		case "/api/v1/historicalTrades":
+			if r.Header.Get("X-MBX-APIKEY") != "ak" || q.Get("fromId") != "5" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"code":-2015,"msg":"Invalid API-key"}`))
+				return
+			}
+			w.Write([]byte(`[{"id":5,"price":"0.2","qty":"1"}]`))
+		case "/api/v1/aggTrades":
+			if q.Get("startTime") != "1600000000000" || q.Get("fromId") != "" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[{"a":1,"p":"0.30000000","q":"2","f":10,"l":12,"T":1600000000001,"m":false,"M":true}]`))
+		}
+	})
+	defer server.Close()
+
+	trades, err := ex.GetTrades("BTRUSDT", 1)
+	if err != nil || len(trades) != 1 || trades[0].Price.Cmp(dec("0.1")) != 0 || trades[0].Qty.Cmp(dec("3.5")) != 0 {
+		t.Fatalf("unexpected trades %v %v", trades, err)
+	}
+	historical, err := ex.GetHistoricalTrades("BTRUSDT", 5, 0)
+	if err != nil || len(historical) != 1 || historical[0].Id != 5 {
+		t.Fatalf("unexpected historical trades %v %v", historical, err)
+	}
+	agg, err := ex.GetAggTrades(AggTradesRequest{Symbol: "BTRUSDT", StartTime: msToTime(1600000000000)})
+	if err != nil || len(agg) != 1 {
+		t.Fatalf("unexpected agg trades %v %v", agg, err)
+	}
+	if agg[0].Price.Cmp(dec("0.3")) != 0 || agg[0].LastTradeId != 12 || !agg[0].IsBestMatch || agg[0].Time != 1600000000001 {
+		t.Fatalf("unexpected agg trade %+v", agg[0])
+	}
+}
+
+func TestPackageGetTradesUsesDefaultClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":7,"price":"0.1","qty":"3.5"}]`))
+	}))
+	defer server.Close()
+	oldHost, oldBaseURL := https, defaultClient.BaseURL
+	defer func() { https, defaultClient.BaseURL = oldHost, oldBaseURL }()
+
+	SetHost(server.URL)
+	trades, err := GetTrades("BTRUSDT", 1)
+	if err != nil || len(trades) != 1 || trades[0].Qty.Cmp(dec("3.5")) != 0 {
+		t.Fatalf("unexpected trades %v %v", trades, err)
+	}
+}
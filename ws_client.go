@@ -0,0 +1,683 @@
+package bitrue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+const DefaultWsURL = "wss://ws.bitrue.com/kline-api/ws"
+
+// DefaultWsMaxChannels is the number of channels carried by one connection
+// before WsClient opens another one.
+const DefaultWsMaxChannels = 20
+
+// DefaultWsHeartbeat is how long a connection may stay silent, neither data
+// nor ping, before it is considered dead and reconnected.
+const DefaultWsHeartbeat = 30 * time.Second
+
+var (
+	ErrWsClosed          = errors.New("bitrue: websocket client closed")
+	ErrAlreadySubscribed = errors.New("bitrue: channel already subscribed")
+	ErrNotSubscribed     = errors.New("bitrue: channel not subscribed")
+	// ErrWsHeartbeat is the WsEvent error of a connection dropped by the
+	// heartbeat watchdog.
+	ErrWsHeartbeat = errors.New("bitrue: websocket heartbeat timeout")
+)
+
+// WsError is an error frame sent by the server, e.g. for a sub event with
+// an unknown channel.
+type WsError struct {
+	Channel string
+	Status  string
+	Msg     string
+}
+
+func (e *WsError) Error() string {
+	return fmt.Sprintf("bitrue: websocket %s on channel %q: %s", e.Status, e.Channel, e.Msg)
+}
+
+// WsReconnectPolicy controls how a WsClient connection is restored, the
+// delay grows exponentially with full jitter like RetryPolicy.
+type WsReconnectPolicy struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// StableAfter is how long a connection must stay up to count as
+	// healthy when no data arrived on it, a data frame always counts.
+	// Until then sessions that end quickly keep the delay growing.
+	StableAfter time.Duration
+	// MaxFailures closes the client after that many consecutive failed
+	// dials or unhealthy sessions, 0 reconnects forever.
+	MaxFailures int
+}
+
+func (p WsReconnectPolicy) backoff(failures int) time.Duration {
+	return RetryPolicy{BaseDelay: p.BaseDelay, MaxDelay: p.MaxDelay}.backoff(failures)
+}
+
+// DefaultWsReconnectPolicy reconnects forever, waiting up to 30s between
+// failed dials.
+var DefaultWsReconnectPolicy = WsReconnectPolicy{
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	StableAfter: 30 * time.Second,
+}
+
+type WsState int
+
+const (
+	WsDisconnected WsState = iota
+	WsConnecting
+	WsConnected
+	// WsClosed is final, it follows Close, the end of the context or
+	// giving up on reconnecting.
+	WsClosed
+)
+
+func (s WsState) String() string {
+	switch s {
+	case WsDisconnected:
+		return "disconnected"
+	case WsConnecting:
+		return "connecting"
+	case WsConnected:
+		return "connected"
+	case WsClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("WsState(%d)", int(s))
+}
+
+// WsEvent reports a connection state change, Err is the dial or read error
+// that caused it, if any. Conn numbers the sockets of a WsClient from 0.
+type WsEvent struct {
+	Conn  int
+	State WsState
+	Err   error
+	Time  time.Time
+}
+
+type WsOption func(*WsClient)
+
+func WithWsURL(u string) WsOption {
+	return func(c *WsClient) {
+		c.URL = u
+	}
+}
+
+func WithWsDialer(dialer *websocket.Dialer) WsOption {
+	return func(c *WsClient) {
+		c.Dialer = dialer
+	}
+}
+
+// WithWsReconnect replaces DefaultWsReconnectPolicy.
+func WithWsReconnect(policy WsReconnectPolicy) WsOption {
+	return func(c *WsClient) {
+		c.Reconnect = policy
+	}
+}
+
+// WithWsHeartbeat sets the heartbeat watchdog interval, 0 disables it.
+func WithWsHeartbeat(d time.Duration) WsOption {
+	return func(c *WsClient) {
+		c.Heartbeat = d
+	}
+}
+
+// WithWsMaxChannels caps the channels per connection, values <= 0 put
+// every channel on one connection.
+func WithWsMaxChannels(n int) WsOption {
+	return func(c *WsClient) {
+		c.MaxChannels = n
+	}
+}
+
+// Subscription receives the data frames of one channel, either through a
+// handler or through C.
+type Subscription struct {
+	Channel string
+	// C is nil for handler subscriptions. It is closed by Unsubscribe and
+	// when the client closes.
+	C <-chan []byte
+
+	client  *WsClient
+	ch      chan []byte
+	handler func(msg []byte)
+
+	mu      sync.Mutex
+	closed  bool
+	dropped uint64
+	err     error
+}
+
+func (s *Subscription) Unsubscribe() error {
+	return s.client.Unsubscribe(s.Channel)
+}
+
+// Err is the *WsError of the last error frame for the channel, it is reset
+// when the server acknowledges the subscription.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// Dropped counts the frames discarded because C was full.
+func (s *Subscription) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+func (s *Subscription) deliver(msg []byte) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	if s.ch != nil {
+		select {
+		case s.ch <- msg:
+		default:
+			s.dropped++
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	s.handler(msg)
+}
+
+func (s *Subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.ch != nil {
+		close(s.ch)
+	}
+}
+
+// WsClient multiplexes channels over connections to the kline-api
+// websocket, opening another connection when MaxChannels is reached. Each
+// connection reconnects with backoff when it drops and subscribes again to
+// its channels. Handlers run on the reader goroutine and must not block.
+type WsClient struct {
+	URL         string
+	Dialer      *websocket.Dialer
+	Reconnect   WsReconnectPolicy
+	MaxChannels int
+	Heartbeat   time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	events chan WsEvent
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+	conns  []*wsConn
+	subs   map[string]*wsConn
+}
+
+// NewWsClient connects in the background, cancelling ctx closes the client
+// like Close.
+func NewWsClient(ctx context.Context, opts ...WsOption) *WsClient {
+	c := &WsClient{
+		URL:         DefaultWsURL,
+		Dialer:      websocket.DefaultDialer,
+		Reconnect:   DefaultWsReconnectPolicy,
+		MaxChannels: DefaultWsMaxChannels,
+		Heartbeat:   DefaultWsHeartbeat,
+		done:        make(chan struct{}),
+		events:      make(chan WsEvent, 16),
+		subs:        make(map[string]*wsConn),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	c.mu.Lock()
+	c.newConn()
+	c.mu.Unlock()
+	go c.shutdown()
+	return c
+}
+
+// Events reports connection state changes. Events are dropped when the
+// channel is not drained, it is closed once every connection is closed.
+func (c *WsClient) Events() <-chan WsEvent {
+	return c.events
+}
+
+// State is the least connected state of all connections.
+func (c *WsClient) State() WsState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := WsClosed
+	for _, conn := range c.conns {
+		if s := conn.getState(); s < state {
+			state = s
+		}
+	}
+	return state
+}
+
+// Close stops reconnecting, closes the connections and waits for the
+// reader goroutines to exit.
+func (c *WsClient) Close() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *WsClient) shutdown() {
+	<-c.ctx.Done()
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.wg.Wait()
+	for _, conn := range c.conns {
+		conn.mu.Lock()
+		for _, sub := range conn.subs {
+			sub.close()
+		}
+		conn.mu.Unlock()
+	}
+	close(c.events)
+	close(c.done)
+}
+
+// Subscribe sends a sub event for channel now, if connected, and after
+// every reconnect. handler receives the decompressed data frames.
+func (c *WsClient) Subscribe(channel string, handler func(msg []byte)) (*Subscription, error) {
+	sub := &Subscription{Channel: channel, client: c, handler: handler}
+	if err := c.subscribe(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// SubscribeChan is Subscribe delivering to a channel with the given
+// buffer, frames are dropped when it is full.
+func (c *WsClient) SubscribeChan(channel string, buffer int) (*Subscription, error) {
+	ch := make(chan []byte, buffer)
+	sub := &Subscription{Channel: channel, C: ch, client: c, ch: ch}
+	if err := c.subscribe(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// wsStreamBuffer is the number of raw frames queued per stream while its
+// decoder is busy.
+const wsStreamBuffer = 256
+
+// wsStream is embedded by the typed streams, KlineStream and TradeStream.
+// Their C is closed after Unsubscribe or when the client closes, it must be
+// drained.
+type wsStream struct {
+	sub *Subscription
+}
+
+func (s *wsStream) Unsubscribe() error {
+	return s.sub.Unsubscribe()
+}
+
+// subscribeStream passes the frames of channel to decode from a single
+// goroutine, logging the frames it fails on, and calls done, if not nil,
+// once the subscription ends.
+func (c *WsClient) subscribeStream(channel string, decode func(msg []byte) error, done func()) (wsStream, error) {
+	sub, err := c.SubscribeChan(channel, wsStreamBuffer)
+	if err != nil {
+		return wsStream{}, err
+	}
+	go func() {
+		if done != nil {
+			defer done()
+		}
+		for msg := range sub.C {
+			if err := decode(msg); err != nil {
+				log.Println(err)
+			}
+		}
+	}()
+	return wsStream{sub: sub}, nil
+}
+
+func (c *WsClient) subscribe(sub *Subscription) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrWsClosed
+	}
+	if _, ok := c.subs[sub.Channel]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadySubscribed, sub.Channel)
+	}
+	var conn *wsConn
+	for _, candidate := range c.conns {
+		if c.MaxChannels <= 0 || candidate.count() < c.MaxChannels {
+			conn = candidate
+			break
+		}
+	}
+	if conn == nil {
+		conn = c.newConn()
+	}
+	c.subs[sub.Channel] = conn
+	conn.add(sub)
+	return nil
+}
+
+// Unsubscribe sends an unsub event for channel and closes its
+// Subscription.
+func (c *WsClient) Unsubscribe(channel string) error {
+	c.mu.Lock()
+	conn, ok := c.subs[channel]
+	if ok {
+		delete(c.subs, channel)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotSubscribed, channel)
+	}
+	conn.remove(channel)
+	return nil
+}
+
+// newConn must be called with c.mu held.
+func (c *WsClient) newConn() *wsConn {
+	conn := &wsConn{
+		client: c,
+		id:     len(c.conns),
+		subs:   make(map[string]*Subscription),
+	}
+	c.conns = append(c.conns, conn)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		conn.run()
+	}()
+	return conn
+}
+
+func (c *WsClient) emit(event WsEvent) {
+	select {
+	case c.events <- event:
+	default:
+	}
+}
+
+func wsMessage(event, channel string) []byte {
+	msg, _ := json.Marshal(map[string]interface{}{
+		"event":  event,
+		"params": map[string]string{"channel": channel, "cb_id": channel},
+	})
+	return msg
+}
+
+// wsConn is one socket of a WsClient and the channels it carries.
+type wsConn struct {
+	client *WsClient
+	id     int
+
+	mu    sync.Mutex
+	conn  *websocket.Conn
+	state WsState
+	subs  map[string]*Subscription
+
+	writeMu sync.Mutex
+}
+
+func (w *wsConn) getState() WsState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+func (w *wsConn) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.subs)
+}
+
+func (w *wsConn) add(sub *Subscription) {
+	w.mu.Lock()
+	w.subs[sub.Channel] = sub
+	conn := w.conn
+	w.mu.Unlock()
+	if conn != nil {
+		// a failed write shows up as a read error, the subscription is
+		// sent again after reconnecting
+		w.write(conn, wsMessage("sub", sub.Channel))
+	}
+}
+
+func (w *wsConn) remove(channel string) {
+	w.mu.Lock()
+	sub := w.subs[channel]
+	delete(w.subs, channel)
+	conn := w.conn
+	w.mu.Unlock()
+	if conn != nil {
+		w.write(conn, wsMessage("unsub", channel))
+	}
+	if sub != nil {
+		sub.close()
+	}
+}
+
+func (w *wsConn) write(conn *websocket.Conn, msg []byte) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+func (w *wsConn) setState(state WsState, err error) {
+	w.mu.Lock()
+	w.state = state
+	w.mu.Unlock()
+	w.client.emit(WsEvent{Conn: w.id, State: state, Err: err, Time: time.Now()})
+}
+
+// run keeps the socket connected until the client closes. Giving up on
+// reconnecting closes the whole client.
+func (w *wsConn) run() {
+	c := w.client
+	defer c.cancel()
+	defer w.setState(WsClosed, nil)
+
+	failures := 0
+	for {
+		w.setState(WsConnecting, nil)
+		conn, _, err := c.Dialer.DialContext(c.ctx, c.URL, nil)
+		if err == nil {
+			start := time.Now()
+			var received bool
+			received, err = w.serve(conn)
+			stable := c.Reconnect.StableAfter > 0 && time.Since(start) >= c.Reconnect.StableAfter
+			if received || stable {
+				failures = 0
+			}
+		}
+		if c.ctx.Err() != nil {
+			return
+		}
+		failures++
+		w.setState(WsDisconnected, err)
+		if c.Reconnect.MaxFailures > 0 && failures >= c.Reconnect.MaxFailures {
+			return
+		}
+		if !sleepCtx(c.ctx, c.Reconnect.backoff(failures-1)) {
+			return
+		}
+	}
+}
+
+// serve subscribes to every channel of w on conn and reads until the
+// connection fails or the client is closed. received reports whether a data
+// frame arrived, which makes the session healthy.
+func (w *wsConn) serve(conn *websocket.Conn) (received bool, err error) {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-w.client.ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+	defer func() {
+		w.mu.Lock()
+		w.conn = nil
+		w.mu.Unlock()
+		conn.Close()
+	}()
+
+	w.mu.Lock()
+	w.conn = conn
+	channels := make([]string, 0, len(w.subs))
+	for channel := range w.subs {
+		channels = append(channels, channel)
+	}
+	w.mu.Unlock()
+	w.setState(WsConnected, nil)
+
+	for _, channel := range channels {
+		if err := w.write(conn, wsMessage("sub", channel)); err != nil {
+			return false, err
+		}
+	}
+	heartbeat := w.client.Heartbeat
+	for {
+		if heartbeat > 0 {
+			conn.SetReadDeadline(time.Now().Add(heartbeat))
+		}
+		msgType, message, err := conn.ReadMessage()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return received, fmt.Errorf("%w: nothing received for %s", ErrWsHeartbeat, heartbeat)
+			}
+			return received, err
+		}
+		if msgType == websocket.BinaryMessage {
+			message, err = ParseGzip(message)
+			if err != nil {
+				continue
+			}
+		}
+		if w.handle(conn, message) == wsFrameData {
+			received = true
+		}
+	}
+}
+
+type wsFrameType int
+
+const (
+	wsFrameUnknown wsFrameType = iota
+	wsFramePing
+	wsFrameAck
+	wsFrameError
+	wsFrameData
+)
+
+// frameType classifies a decompressed frame by its JSON fields, so a data
+// payload that happens to contain "ping" is never answered with a pong.
+func frameType(msg []byte) wsFrameType {
+	if !gjson.ValidBytes(msg) {
+		return wsFrameUnknown
+	}
+	frame := gjson.ParseBytes(msg)
+	if !frame.IsObject() {
+		return wsFrameUnknown
+	}
+	if frame.Get("ping").Exists() {
+		return wsFramePing
+	}
+	status := frame.Get("status")
+	if status.Exists() && status.String() != "ok" {
+		return wsFrameError
+	}
+	if frame.Get("event_rep").Exists() {
+		return wsFrameAck
+	}
+	if frame.Get("channel").Exists() && (frame.Get("tick").Exists() || frame.Get("data").Exists()) {
+		return wsFrameData
+	}
+	return wsFrameUnknown
+}
+
+func (w *wsConn) handle(conn *websocket.Conn, msg []byte) wsFrameType {
+	kind := frameType(msg)
+	switch kind {
+	case wsFramePing:
+		pong := `{"pong":` + gjson.GetBytes(msg, "ping").Raw + `}`
+		w.write(conn, []byte(pong))
+	case wsFrameAck:
+		if sub := w.route(msg); sub != nil {
+			sub.setErr(nil)
+		}
+	case wsFrameError:
+		err := &WsError{
+			Channel: gjson.GetBytes(msg, "channel").String(),
+			Status:  gjson.GetBytes(msg, "status").String(),
+			Msg:     string(msg),
+		}
+		sub := w.route(msg)
+		if sub == nil {
+			log.Println(err)
+		} else {
+			err.Channel = sub.Channel
+			sub.setErr(err)
+		}
+	case wsFrameData:
+		if sub := w.route(msg); sub != nil {
+			sub.deliver(msg)
+		}
+	default:
+		log.Println("websocket unknown frame:", string(msg))
+	}
+	return kind
+}
+
+// route finds the subscription of a frame, acks and errors carry the cb_id
+// of the sub event, data frames only the channel.
+func (w *wsConn) route(msg []byte) *Subscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sub, ok := w.subs[gjson.GetBytes(msg, "cb_id").String()]; ok {
+		return sub
+	}
+	return w.subs[gjson.GetBytes(msg, "channel").String()]
+}
+
+// sleepCtx reports false when ctx ends first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,368 @@
+package bitrue
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+func gzipFrame(t *testing.T, msg string) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write([]byte(msg))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// newTestWsServer calls handler with every accepted connection, the server
+// side of the kline-api socket.
+func newTestWsServer(t *testing.T, handler func(conn *websocket.Conn)) (*httptest.Server, string) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		handler(conn)
+	}))
+	return server, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+var fastWsReconnect = WsReconnectPolicy{BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func waitWsState(t *testing.T, client *WsClient, state WsState) WsEvent {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-client.Events():
+			if event.State == state {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", state)
+		}
+	}
+}
+
+func TestWsClientReconnect(t *testing.T) {
+	subs := make(chan string, 10)
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		channel := gjson.GetBytes(msg, "params.channel").String()
+		subs <- channel
+		conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"event_rep":"subed","channel":"`+channel+`","status":"ok"}`))
+		conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"channel":"other","tick":{}}`))
+		conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"channel":"`+channel+`","tick":{"close":1}}`))
+		// drop the connection after the first data frame
+	})
+	defer server.Close()
+
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(fastWsReconnect))
+	data := make(chan []byte, 10)
+	if _, err := client.Subscribe("market_btrusdt_kline_1min", func(msg []byte) { data <- msg }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Subscribe("market_btrusdt_kline_1min", nil); err == nil {
+		t.Fatal("expected duplicate subscription error")
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case channel := <-subs:
+			if channel != "market_btrusdt_kline_1min" {
+				t.Fatalf("unexpected sub %q", channel)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no subscription on connection %d", i+1)
+		}
+		select {
+		case msg := <-data:
+			if gjson.GetBytes(msg, "tick.close").Int() != 1 {
+				t.Fatalf("unexpected data %s", msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no data on connection %d", i+1)
+		}
+	}
+
+	client.Close()
+	if client.State() != WsClosed {
+		t.Fatalf("unexpected state %s", client.State())
+	}
+	if _, err := client.Subscribe("market_ethbtc_kline_1min", nil); err != ErrWsClosed {
+		t.Fatalf("expected ErrWsClosed, got %v", err)
+	}
+}
+
+func TestWsClientContextCancel(t *testing.T) {
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewWsClient(ctx, WithWsURL(u), WithWsReconnect(fastWsReconnect))
+	waitWsState(t, client, WsConnected)
+	cancel()
+	waitWsState(t, client, WsClosed)
+}
+
+func TestWsClientGiveUp(t *testing.T) {
+	policy := fastWsReconnect
+	policy.MaxFailures = 2
+	client := NewWsClient(context.Background(), WithWsURL("ws://127.0.0.1:1/"), WithWsReconnect(policy))
+	event := waitWsState(t, client, WsDisconnected)
+	if event.Err == nil {
+		t.Fatal("expected dial error")
+	}
+	waitWsState(t, client, WsClosed)
+}
+
+func TestWsClientShortSessionsEscalate(t *testing.T) {
+	var mu sync.Mutex
+	sessions := 0
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		// accept, then close before anything is sent
+		mu.Lock()
+		sessions++
+		mu.Unlock()
+	})
+	defer server.Close()
+
+	policy := WsReconnectPolicy{BaseDelay: 20 * time.Millisecond, MaxDelay: time.Second, StableAfter: time.Minute, MaxFailures: 4}
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(policy))
+	if _, err := client.Subscribe("market_btrusdt_kline_1min", func([]byte) {}); err != nil {
+		t.Fatal(err)
+	}
+	waitWsState(t, client, WsClosed)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sessions != 4 {
+		t.Fatalf("expected 4 sessions before giving up, got %d", sessions)
+	}
+}
+
+func TestWsClientDataResetsFailures(t *testing.T) {
+	sessions := make(chan struct{}, 100)
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		channel := gjson.GetBytes(msg, "params.channel").String()
+		conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"channel":"`+channel+`","tick":{}}`))
+		sessions <- struct{}{}
+	})
+	defer server.Close()
+
+	policy := fastWsReconnect
+	policy.MaxFailures = 2
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(policy))
+	defer client.Close()
+	if _, err := client.Subscribe("market_btrusdt_kline_1min", func([]byte) {}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-sessions:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no session %d, state %s", i+1, client.State())
+		}
+	}
+}
+
+func TestWsClientMultiplex(t *testing.T) {
+	type frame struct {
+		conn    *websocket.Conn
+		event   string
+		channel string
+	}
+	frames := make(chan frame, 10)
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			channel := gjson.GetBytes(msg, "params.channel").String()
+			frames <- frame{conn, gjson.GetBytes(msg, "event").String(), channel}
+			if gjson.GetBytes(msg, "event").String() == "sub" {
+				conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"channel":"`+channel+`","tick":{}}`))
+			}
+		}
+	})
+	defer server.Close()
+
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(fastWsReconnect), WithWsMaxChannels(2))
+	defer client.Close()
+	var subs []*Subscription
+	for _, channel := range []string{"a", "b", "c"} {
+		sub, err := client.SubscribeChan(channel, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+	}
+
+	conns := make(map[string]*websocket.Conn)
+	for i := 0; i < 3; i++ {
+		select {
+		case f := <-frames:
+			conns[f.channel] = f.conn
+		case <-time.After(5 * time.Second):
+			t.Fatal("missing sub")
+		}
+	}
+	if conns["a"] != conns["b"] || conns["a"] == conns["c"] {
+		t.Fatal("expected a and b on one connection and c on another")
+	}
+	for _, sub := range subs {
+		select {
+		case msg := <-sub.C:
+			if gjson.GetBytes(msg, "channel").String() != sub.Channel {
+				t.Fatalf("%s got %s", sub.Channel, msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no data for %s", sub.Channel)
+		}
+	}
+
+	if err := subs[0].Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case f := <-frames:
+		if f.event != "unsub" || f.channel != "a" || f.conn != conns["a"] {
+			t.Fatalf("unexpected frame %+v", f)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("missing unsub")
+	}
+	if _, ok := <-subs[0].C; ok {
+		t.Fatal("expected closed subscription")
+	}
+	if err := client.Unsubscribe("a"); !errors.Is(err, ErrNotSubscribed) {
+		t.Fatalf("expected ErrNotSubscribed, got %v", err)
+	}
+	// the free slot on the first connection is reused
+	if _, err := client.SubscribeChan("d", 1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case f := <-frames:
+		if f.channel != "d" || f.conn != conns["b"] {
+			t.Fatalf("unexpected frame %+v", f)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("missing sub")
+	}
+}
+
+func TestFrameType(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want wsFrameType
+	}{
+		{`{"ping":1600000000000}`, wsFramePing},
+		{`{"channel":"market_ping_trade_ticker","tick":{"data":"ping"}}`, wsFrameData},
+		{`{"xxping":1}`, wsFrameUnknown},
+		{`{"event_rep":"subed","cb_id":"a","status":"ok"}`, wsFrameAck},
+		{`{"event_rep":"subed","cb_id":"a","status":"error"}`, wsFrameError},
+		{`{"status":"error","err-msg":"invalid"}`, wsFrameError},
+		{`pi`, wsFrameUnknown},
+		{``, wsFrameUnknown},
+		{`["ping"]`, wsFrameUnknown},
+	}
+	for _, test := range tests {
+		if got := frameType([]byte(test.msg)); got != test.want {
+			t.Errorf("frameType(%s) = %d, want %d", test.msg, got, test.want)
+		}
+	}
+}
+
+func TestWsClientPingAndErrors(t *testing.T) {
+	pongs := make(chan string, 1)
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"ping":12345}`))
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if gjson.GetBytes(msg, "pong").Exists() {
+				pongs <- string(msg)
+				continue
+			}
+			cbID := gjson.GetBytes(msg, "params.cb_id").String()
+			conn.WriteMessage(websocket.BinaryMessage, gzipFrame(t, `{"event_rep":"subed","cb_id":"`+cbID+`","status":"error","err-msg":"unknown channel"}`))
+		}
+	})
+	defer server.Close()
+
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(fastWsReconnect))
+	defer client.Close()
+	select {
+	case pong := <-pongs:
+		if pong != `{"pong":12345}` {
+			t.Fatalf("unexpected pong %s", pong)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pong")
+	}
+
+	sub, err := client.Subscribe("market_nope_kline_1min", func([]byte) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for sub.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	var wsErr *WsError
+	if !errors.As(sub.Err(), &wsErr) || wsErr.Channel != "market_nope_kline_1min" || wsErr.Status != "error" {
+		t.Fatalf("unexpected error %v", sub.Err())
+	}
+}
+
+func TestWsClientHeartbeat(t *testing.T) {
+	server, u := newTestWsServer(t, func(conn *websocket.Conn) {
+		// never send anything
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	client := NewWsClient(context.Background(), WithWsURL(u), WithWsReconnect(fastWsReconnect), WithWsHeartbeat(50*time.Millisecond))
+	defer client.Close()
+	waitWsState(t, client, WsConnected)
+	event := waitWsState(t, client, WsDisconnected)
+	if !errors.Is(event.Err, ErrWsHeartbeat) {
+		t.Fatalf("expected ErrWsHeartbeat, got %v", event.Err)
+	}
+	waitWsState(t, client, WsConnected)
+}